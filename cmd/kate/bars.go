@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/victorl2/kate-backtester/kate"
+)
+
+func runBars(args []string) error {
+	flags := flag.NewFlagSet("bars", flag.ExitOnError)
+	input := flags.String("data", "", "csv file with the price data")
+	output := flags.String("out", "", "csv file where the bars are written")
+	kind := flags.String("type", "time", "type of bars: time, volume, dollar, range, renko or heikin-ashi")
+	timeframe := flags.Duration("timeframe", time.Hour, "timeframe of the time bars (5m, 1h, 24h...)")
+	size := flags.Float64("size", 0, "threshold of the volume and dollar bars, range of the range bars or size of the renko bricks")
+	flags.Parse(args)
+
+	if *input == "" || *output == "" {
+		return fmt.Errorf("the -data and -out flags are required")
+	}
+	data, err := kate.PricesFromCSV(*input)
+	if err != nil {
+		return err
+	}
+
+	var bars *kate.DataHandler
+	switch *kind {
+	case "time":
+		bars, err = data.Resample(*timeframe)
+	case "volume":
+		bars, err = data.VolumeBars(*size)
+	case "dollar":
+		bars, err = data.DollarBars(*size)
+	case "range":
+		bars, err = data.RangeBars(*size)
+	case "renko":
+		bars, err = data.Renko(*size)
+	case "heikin-ashi":
+		bars = data.HeikinAshi()
+	default:
+		return fmt.Errorf("unknown type of bars %v", *kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := bars.SaveCSV(*output); err != nil {
+		return err
+	}
+	fmt.Printf("%v candles converted to %v %v bars in %v\n", len(data.Prices), len(bars.Prices), *kind, *output)
+	return nil
+}
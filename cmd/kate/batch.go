@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/victorl2/kate-backtester/kate"
+)
+
+//backtestFlags are the settings of the simulation shared by the commands that run backtests
+type backtestFlags struct {
+	balance     float64
+	tradeAmount float64
+	slippage    float64
+	leverage    uint
+	stop        float64
+	target      float64
+}
+
+func (settings *backtestFlags) register(flags *flag.FlagSet) {
+	settings.registerAccount(flags)
+	flags.UintVar(&settings.leverage, "leverage", 30, "leverage of the positions")
+	flags.Float64Var(&settings.stop, "stop", 0.5, "stoploss distance in percentage of the entry price")
+	flags.Float64Var(&settings.target, "target", 0.5, "takeprofit distance in percentage of the entry price")
+}
+
+//registerAccount registers only the settings of the account, used by commands that don't run the momentum strategy
+func (settings *backtestFlags) registerAccount(flags *flag.FlagSet) {
+	flags.Float64Var(&settings.balance, "balance", 1000, "initial balance")
+	flags.Float64Var(&settings.tradeAmount, "amount", 0, "fixed amount used to open each position, zero uses 1% of the balance")
+	flags.Float64Var(&settings.slippage, "slippage", 0, "slippage percentage applied to market orders")
+}
+
+//setup applies the settings to a backtester
+func (settings *backtestFlags) setup(backtester *kate.Backtester) {
+	backtester.SetBalance(settings.balance)
+	backtester.SetFixedTradeAmount(settings.tradeAmount)
+	backtester.SetSlippagePercentage(settings.slippage)
+}
+
+//newStrategy creates the momentum strategy configured by the flags
+func (settings *backtestFlags) newStrategy() kate.Strategy {
+	return &momentumStrategy{leverage: settings.leverage, stop: settings.stop, target: settings.target}
+}
+
+func runBatch(args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	pattern := flags.String("data", "testdata/*.csv", "glob of the csv files with the price data")
+	workers := flags.Int("workers", 0, "amount of files processed in parallel, zero uses all CPUs")
+	var settings backtestFlags
+	settings.register(flags)
+	flags.Parse(args)
+
+	batch := kate.NewBatch(settings.newStrategy)
+	batch.Setup = settings.setup
+	if *workers > 0 {
+		batch.Workers = *workers
+	}
+
+	results, err := batch.RunGlob(*pattern)
+	if err != nil {
+		return err
+	}
+	fmt.Println(results)
+	if len(results.Failed()) == len(results) {
+		return fmt.Errorf("none of the %v files could be processed", len(results))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+//Command kate runs backtests from the command line
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+//command is a subcommand of the cli that receives the arguments after its name
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{"batch", "runs the same strategy configuration on every csv file matching a glob", runBatch},
+	{"signals", "backtests the precomputed signals of a csv or json lines file on a csv of prices", runSignals},
+	{"process", "backtests a strategy running in a external process that exchanges json lines on stdin/stdout", runProcess},
+	{"rules", "backtests a strategy defined by the entry and exit rules of a json file", runRules},
+	{"bars", "resamples the candles of a csv file or builds alternative bars and writes them to csv", runBars},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: kate <command> [flags]\n\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10v %v\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'kate <command> -h' for the flags of a command.")
+}
@@ -0,0 +1,47 @@
+package main
+
+import "github.com/victorl2/kate-backtester/kate"
+
+//momentumStrategy opens long positions when the close price rises with fixed stoploss and takeprofit distances
+type momentumStrategy struct {
+	leverage     uint
+	stop         float64 //percentage of the entry price
+	target       float64 //percentage of the entry price
+	lastPrice    *kate.DataPoint
+	currentPrice *kate.DataPoint
+}
+
+//OnStart forgets the prices of the previous run
+func (stg *momentumStrategy) OnStart(config kate.RunConfig) {
+	stg.lastPrice, stg.currentPrice = nil, nil
+}
+
+//PreProcessIndicators keeps the last two prices
+func (stg *momentumStrategy) PreProcessIndicators(latestPrice kate.DataPoint) {
+	stg.lastPrice = stg.currentPrice
+	stg.currentPrice = &latestPrice
+}
+
+//OpenNewPosition opens a long position when the close price rises
+func (stg *momentumStrategy) OpenNewPosition(latestPrice kate.DataPoint) *kate.OpenPositionEvt {
+	if stg.lastPrice != nil && stg.currentPrice.Close() > stg.lastPrice.Close() {
+		return &kate.OpenPositionEvt{Direction: kate.LONG, Leverage: stg.leverage}
+	}
+	return nil
+}
+
+//SetStoploss defines the stoploss once for the open position
+func (stg *momentumStrategy) SetStoploss(openPosition kate.Position) *kate.StoplossEvt {
+	if openPosition.Stoploss <= 0 {
+		return &kate.StoplossEvt{Price: openPosition.EntryPrice * (1 - stg.stop/100)}
+	}
+	return nil
+}
+
+//SetTakeProfit defines the takeprofit once for the open position
+func (stg *momentumStrategy) SetTakeProfit(openPosition kate.Position) *kate.TakeProfitEvt {
+	if openPosition.TakeProfit <= 0 {
+		return &kate.TakeProfitEvt{Price: openPosition.EntryPrice * (1 + stg.target/100)}
+	}
+	return nil
+}
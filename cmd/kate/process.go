@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/victorl2/kate-backtester/kate"
+)
+
+func runProcess(args []string) error {
+	flags := flag.NewFlagSet("process", flag.ExitOnError)
+	prices := flags.String("data", "", "csv file with the price data")
+	timeout := flags.Duration("timeout", 10*time.Second, "maximum time the process has to read each message and answer each candle")
+	lookback := flags.Int("lookback", 0, "candles received by the process before it can open positions")
+	var settings backtestFlags
+	settings.registerAccount(flags)
+	flags.Parse(args)
+
+	if *prices == "" || flags.NArg() == 0 {
+		return fmt.Errorf("the -data flag and the command of the strategy are required")
+	}
+	data, err := kate.PricesFromCSV(*prices)
+	if err != nil {
+		return err
+	}
+
+	strategy := kate.NewProcessStrategy(flags.Arg(0), flags.Args()[1:]...)
+	strategy.Timeout = *timeout
+	backtester := kate.NewBacktester(strategy, data)
+	settings.setup(backtester)
+	backtester.SetLookback(*lookback)
+	stats := backtester.Run()
+	if strategy.Err() != nil {
+		return strategy.Err()
+	}
+	fmt.Println(stats)
+	return nil
+}
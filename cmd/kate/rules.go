@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/victorl2/kate-backtester/kate"
+)
+
+func runRules(args []string) error {
+	flags := flag.NewFlagSet("rules", flag.ExitOnError)
+	prices := flags.String("data", "", "csv file with the price data")
+	path := flags.String("rules", "", "json file with the entry and exit rules of the strategy")
+	export := flags.String("export", "", "directory where the trades, orders and equity curve are exported as csv")
+	var settings backtestFlags
+	settings.registerAccount(flags)
+	flags.Parse(args)
+
+	if *prices == "" || *path == "" {
+		return fmt.Errorf("the -data and -rules flags are required")
+	}
+	data, err := kate.PricesFromCSV(*prices)
+	if err != nil {
+		return err
+	}
+	config, err := kate.LoadRules(*path)
+	if err != nil {
+		return err
+	}
+	strategy, err := config.Compile()
+	if err != nil {
+		return err
+	}
+
+	backtester := kate.NewBacktester(strategy, data)
+	settings.setup(backtester)
+	stats := backtester.Run()
+	if config.Name != "" {
+		fmt.Println(config.Name)
+	}
+	fmt.Println(stats)
+	if *export != "" {
+		return stats.ExportCSV(*export)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/victorl2/kate-backtester/kate"
+)
+
+func runSignals(args []string) error {
+	flags := flag.NewFlagSet("signals", flag.ExitOnError)
+	prices := flags.String("data", "", "csv file with the price data (requires the close_time column)")
+	path := flags.String("signals", "", "csv or json lines file with the signals")
+	export := flags.String("export", "", "directory where the trades, orders and equity curve are exported as csv")
+	var settings backtestFlags
+	settings.registerAccount(flags)
+	flags.Parse(args)
+
+	if *prices == "" || *path == "" {
+		return fmt.Errorf("the -data and -signals flags are required")
+	}
+	data, err := kate.PricesFromCSV(*prices)
+	if err != nil {
+		return err
+	}
+	signals, err := kate.SignalsFromFile(*path)
+	if err != nil {
+		return err
+	}
+	strategy, err := kate.NewSignalStrategy(signals, data)
+	if err != nil {
+		return err
+	}
+
+	backtester := kate.NewBacktester(strategy, data)
+	settings.setup(backtester)
+	stats := backtester.Run()
+	fmt.Println(stats)
+	if *export != "" {
+		return stats.ExportCSV(*export)
+	}
+	return nil
+}
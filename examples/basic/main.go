@@ -24,6 +24,11 @@ func main() {
 	fmt.Println(backtester.Run())
 }
 
+//OnStart forgets the prices of the previous run
+func (stg *SimpleStrategy) OnStart(config kate.RunConfig) {
+	stg.lastPrice, stg.currentPrice = nil, nil
+}
+
 //PreProcessIndicators allows the pre processing of indicators
 func (stg *SimpleStrategy) PreProcessIndicators(latestPrice kate.DataPoint) {
 	//No indicators to process
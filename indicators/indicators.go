@@ -0,0 +1,120 @@
+//Package indicators contains streaming technical indicators updated in constant time with each new candle.
+//Every indicator reports when its warm-up period is complete, the values before it are not meaningful.
+package indicators
+
+import "time"
+
+//Candle is the price data consumed by the indicators, kate.DataPoint implements it
+type Candle interface {
+	Open() float64
+	High() float64
+	Low() float64
+	Close() float64
+	Volume() float64
+	Time() time.Time
+}
+
+//Indicator is a technical indicator updated with each new candle
+type Indicator interface {
+	//Update adds the latest candle to the calculation of the indicator
+	Update(candle Candle)
+
+	//Ready checks if enough candles were received to complete the warm-up period
+	Ready() bool
+}
+
+//period ensures that periods lower than 1 are treated as 1
+func period(value int) int {
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+//window is a fixed capacity ring buffer keeping the latest values
+type window struct {
+	values []float64
+	start  int
+	size   int
+}
+
+func newWindow(capacity int) *window {
+	return &window{values: make([]float64, period(capacity))}
+}
+
+//push adds the value returning the oldest value removed when the window was full
+func (w *window) push(value float64) (removed float64, full bool) {
+	if w.size == len(w.values) {
+		removed = w.values[w.start]
+		w.values[w.start] = value
+		w.start = (w.start + 1) % len(w.values)
+		return removed, true
+	}
+	w.values[(w.start+w.size)%len(w.values)] = value
+	w.size++
+	return 0, false
+}
+
+//oldest is the first value of the window, zero when it is empty
+func (w *window) oldest() float64 {
+	return w.values[w.start]
+}
+
+func (w *window) full() bool {
+	return w.size == len(w.values)
+}
+
+//extremes tracks the highest and lowest values of the latest candles with monotonic queues (amortized O(1))
+type extremes struct {
+	capacity    int
+	count       int
+	highs, lows []indexed
+}
+
+type indexed struct {
+	index int
+	value float64
+}
+
+func newExtremes(capacity int) *extremes {
+	return &extremes{capacity: period(capacity)}
+}
+
+func (e *extremes) push(high, low float64) {
+	for len(e.highs) > 0 && e.highs[len(e.highs)-1].value <= high {
+		e.highs = e.highs[:len(e.highs)-1]
+	}
+	e.highs = append(e.highs, indexed{e.count, high})
+	for len(e.lows) > 0 && e.lows[len(e.lows)-1].value >= low {
+		e.lows = e.lows[:len(e.lows)-1]
+	}
+	e.lows = append(e.lows, indexed{e.count, low})
+
+	e.count++
+	if e.highs[0].index <= e.count-1-e.capacity {
+		e.highs = e.highs[1:]
+	}
+	if e.lows[0].index <= e.count-1-e.capacity {
+		e.lows = e.lows[1:]
+	}
+}
+
+//highest is the highest value of the window, zero when it is empty
+func (e *extremes) highest() float64 {
+	if len(e.highs) == 0 {
+		return 0
+	}
+	return e.highs[0].value
+}
+
+//lowest is the lowest value of the window, zero when it is empty
+func (e *extremes) lowest() float64 {
+	if len(e.lows) == 0 {
+		return 0
+	}
+	return e.lows[0].value
+}
+
+func (e *extremes) full() bool {
+	return e.count >= e.capacity
+}
@@ -0,0 +1,166 @@
+package indicators
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type candle struct {
+	open, high, low, close, volume float64
+	closeTime                      time.Time
+}
+
+func (c candle) Open() float64   { return c.open }
+func (c candle) High() float64   { return c.high }
+func (c candle) Low() float64    { return c.low }
+func (c candle) Close() float64  { return c.close }
+func (c candle) Volume() float64 { return c.volume }
+func (c candle) Time() time.Time { return c.closeTime }
+
+//loadCandles reads the OHLCV csv files from the testdata with the close time in unix seconds
+func loadCandles(t *testing.T, path string) []candle {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	lines, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var candles []candle
+	for _, line := range lines[1:] {
+		var values [6]float64
+		for i := range values {
+			if values[i], err = strconv.ParseFloat(line[i], 64); err != nil {
+				t.Fatal(err)
+			}
+		}
+		candles = append(candles, candle{values[0], values[1], values[2], values[3], values[4],
+			time.Unix(int64(values[5]), 0).UTC()})
+	}
+	return candles
+}
+
+func TestIndicatorsReferenceValues(t *testing.T) {
+	candles := loadCandles(t, "../testdata/ETHUSD2.csv")
+	sma, ema, wma, rsi := NewSMA(20), NewEMA(20), NewWMA(20), NewRSI(14)
+	macd, bollinger, atr, stochastic := NewMACD(12, 26, 9), NewBollinger(20, 2), NewATR(14), NewStochastic(14, 3)
+	adx, obv, vwap, superTrend := NewADX(14), NewOBV(), NewVWAP(), NewSuperTrend(10, 3)
+	keltner, donchian, ichimoku := NewKeltner(20, 10, 2), NewDonchian(20), NewIchimoku(9, 26, 52)
+	all := []Indicator{sma, ema, wma, rsi, macd, bollinger, atr, stochastic, adx, obv, vwap, superTrend, keltner,
+		donchian, ichimoku}
+
+	//reference values calculated with straightforward implementations over the full window of each candle,
+	//regenerated with testdata/indicators_reference.py
+	indexes := [3]int{99, 500, len(candles) - 1}
+	tests := []struct {
+		name     string
+		value    func() float64
+		expected [3]float64
+	}{
+		{"SMA(20)", sma.Value, [3]float64{2150.8375, 2143.545, 2135.3650000000002}},
+		{"EMA(20)", ema.Value, [3]float64{2149.7205140917463, 2143.974020301721, 2135.8752391233306}},
+		{"WMA(20)", wma.Value, [3]float64{2150.0069047619045, 2144.502619047619, 2134.6559523809524}},
+		{"RSI(14)", rsi.Value, [3]float64{39.94506544688261, 54.17301877457066, 45.43435566853529}},
+		{"MACD", macd.MACD, [3]float64{0.15588172888465124, 1.1962075621731856, -1.5025569618928785}},
+		{"MACD signal", macd.Signal, [3]float64{0.850868352917092, 1.2250053657635878, -1.9557209298681166}},
+		{"MACD histogram", macd.Histogram, [3]float64{-0.6949866240324407, -0.028797803590402182, 0.45316396797523817}},
+		{"Bollinger upper", bollinger.Upper, [3]float64{2154.676494008852, 2147.5701583819773, 2141.0612355990606}},
+		{"Bollinger middle", bollinger.Middle, [3]float64{2150.8375, 2143.545, 2135.3650000000002}},
+		{"Bollinger lower", bollinger.Lower, [3]float64{2146.9985059911482, 2139.519841618023, 2129.66876440094}},
+		{"ATR(14)", atr.Value, [3]float64{1.2547047701708658, 0.9798074864395315, 1.0627855417419267}},
+		{"Stochastic K", stochastic.K, [3]float64{0.0, 36.97478991596446, 76.43678160919805}},
+		{"Stochastic D", stochastic.D, [3]float64{3.5294117647080223, 56.919524566583306, 72.48999809487557}},
+		{"ADX(14)", adx.Value, [3]float64{22.560598489977924, 28.07594191978215, 35.015875342428146}},
+		{"ADX +DI", adx.PlusDI, [3]float64{21.89209127070089, 47.40583141721842, 30.787365444071064}},
+		{"ADX -DI", adx.MinusDI, [3]float64{35.16439566211683, 25.915623169429416, 45.2930710529067}},
+		{"OBV", obv.Value, [3]float64{-5667498.0, -19278290.0, -68612924.0}},
+		{"VWAP", vwap.Value, [3]float64{2142.7273809966255, 2136.867388150379, 2142.082366643051}},
+		{"SuperTrend", superTrend.Value, [3]float64{2151.5724430326877, 2143.9719693864095, 2132.585604010369}},
+		{"Keltner upper", keltner.Upper, [3]float64{2152.1521427802045, 2145.9476808921054, 2137.8181697830846}},
+		{"Keltner middle", keltner.Middle, [3]float64{2149.7205140917463, 2143.974020301721, 2135.8752391233306}},
+		{"Keltner lower", keltner.Lower, [3]float64{2147.288885403288, 2142.0003597113364, 2133.9323084635766}},
+		{"Donchian upper", donchian.Upper, [3]float64{2155.0, 2147.95, 2140.95}},
+		{"Donchian middle", donchian.Middle, [3]float64{2150.85, 2143.975, 2134.925}},
+		{"Donchian lower", donchian.Lower, [3]float64{2146.7, 2140.0, 2128.9}},
+		{"Ichimoku conversion", ichimoku.Conversion, [3]float64{2148.85, 2145.8, 2134.65}},
+		{"Ichimoku base", ichimoku.Base, [3]float64{2150.85, 2143.975, 2135.4750000000004}},
+		{"Ichimoku span A", ichimoku.SpanA, [3]float64{2144.4125, 2140.1875, 2142.2375}},
+		{"Ichimoku span B", ichimoku.SpanB, [3]float64{2138.775, 2134.9, 2143.75}},
+	}
+
+	checkpoint := 0
+	for i, candle := range candles {
+		for _, indicator := range all {
+			indicator.Update(candle)
+		}
+		if i != indexes[checkpoint] {
+			continue
+		}
+		for _, test := range tests {
+			if value := test.value(); math.Abs(value-test.expected[checkpoint]) > 1e-6 {
+				t.Errorf("%v on candle %v: expected %v, found %v", test.name, i, test.expected[checkpoint], value)
+			}
+		}
+		if checkpoint++; checkpoint == len(indexes) {
+			break
+		}
+	}
+}
+
+func TestIndicatorsWarmUp(t *testing.T) {
+	candles := loadCandles(t, "../testdata/ETHUSD2.csv")
+	tests := []struct {
+		name      string
+		indicator Indicator
+		warmUp    int //amount of candles required to be ready
+	}{
+		{"SMA", NewSMA(20), 20},
+		{"EMA", NewEMA(20), 20},
+		{"WMA", NewWMA(20), 20},
+		{"RSI", NewRSI(14), 15},
+		{"MACD", NewMACD(12, 26, 9), 34},
+		{"Bollinger", NewBollinger(20, 2), 20},
+		{"ATR", NewATR(14), 14},
+		{"Stochastic", NewStochastic(14, 3), 16},
+		{"ADX", NewADX(14), 28},
+		{"OBV", NewOBV(), 1},
+		{"VWAP", NewVWAP(), 1},
+		{"SuperTrend", NewSuperTrend(10, 3), 10},
+		{"Keltner", NewKeltner(20, 10, 2), 20},
+		{"Donchian", NewDonchian(20), 20},
+		{"Ichimoku", NewIchimoku(9, 26, 52), 78},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < test.warmUp; i++ {
+			if test.indicator.Ready() {
+				t.Errorf("%v should not be ready after %v candles", test.name, i)
+			}
+			test.indicator.Update(candles[i])
+		}
+		if !test.indicator.Ready() {
+			t.Errorf("%v should be ready after %v candles", test.name, test.warmUp)
+		}
+	}
+}
+
+func TestVWAPDailyReset(t *testing.T) {
+	vwap := NewVWAP()
+	day := time.Date(2021, 4, 11, 23, 59, 0, 0, time.UTC)
+	vwap.Update(candle{10, 10, 10, 10, 5, day})
+	vwap.Update(candle{20, 20, 20, 20, 5, day.Add(30 * time.Second)})
+	if vwap.Value() != 15 {
+		t.Errorf("Expected the average of the day to be 15, found %v", vwap.Value())
+	}
+	vwap.Update(candle{30, 30, 30, 30, 1, day.Add(time.Minute)})
+	if vwap.Value() != 30 {
+		t.Errorf("Expected the average to restart on the next day, found %v", vwap.Value())
+	}
+}
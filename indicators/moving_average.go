@@ -0,0 +1,125 @@
+package indicators
+
+//SMA is the simple moving average of the close prices
+type SMA struct {
+	values *window
+	sum    float64
+}
+
+//EMA is the exponential moving average of the close prices, seeded with the simple average of the first period
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+//WMA is the linearly weighted moving average of the close prices, the latest price has the highest weight
+type WMA struct {
+	values   *window
+	sum      float64
+	weighted float64
+}
+
+//NewSMA creates a simple moving average of the period (candles)
+func NewSMA(length int) *SMA {
+	return &SMA{values: newWindow(length)}
+}
+
+//Update adds the close price of the candle
+func (sma *SMA) Update(candle Candle) {
+	sma.Add(candle.Close())
+}
+
+//Add adds a value to the average
+func (sma *SMA) Add(value float64) {
+	removed, _ := sma.values.push(value)
+	sma.sum += value - removed
+}
+
+//Ready checks if the average has a full period of values
+func (sma *SMA) Ready() bool {
+	return sma.values.full()
+}
+
+//Value is the average of the latest values
+func (sma *SMA) Value() float64 {
+	if sma.values.size == 0 {
+		return 0
+	}
+	return sma.sum / float64(sma.values.size)
+}
+
+//NewEMA creates a exponential moving average of the period (candles) with a smoothing factor of 2/(period+1)
+func NewEMA(length int) *EMA {
+	length = period(length)
+	return &EMA{period: length, alpha: 2 / float64(length+1)}
+}
+
+//newWilderEMA creates a exponential moving average with the smoothing factor 1/period used by Welles Wilder
+func newWilderEMA(length int) *EMA {
+	length = period(length)
+	return &EMA{period: length, alpha: 1 / float64(length)}
+}
+
+//Update adds the close price of the candle
+func (ema *EMA) Update(candle Candle) {
+	ema.Add(candle.Close())
+}
+
+//Add adds a value to the average
+func (ema *EMA) Add(value float64) {
+	ema.count++
+	if ema.count <= ema.period {
+		ema.value += (value - ema.value) / float64(ema.count)
+		return
+	}
+	ema.value += ema.alpha * (value - ema.value)
+}
+
+//Ready checks if the average received a full period of values
+func (ema *EMA) Ready() bool {
+	return ema.count >= ema.period
+}
+
+//Value is the current average
+func (ema *EMA) Value() float64 {
+	return ema.value
+}
+
+//NewWMA creates a weighted moving average of the period (candles)
+func NewWMA(length int) *WMA {
+	return &WMA{values: newWindow(length)}
+}
+
+//Update adds the close price of the candle
+func (wma *WMA) Update(candle Candle) {
+	wma.Add(candle.Close())
+}
+
+//Add adds a value to the average
+func (wma *WMA) Add(value float64) {
+	if wma.values.full() {
+		wma.weighted += float64(wma.values.size)*value - wma.sum
+		removed, _ := wma.values.push(value)
+		wma.sum += value - removed
+		return
+	}
+	wma.values.push(value)
+	wma.weighted += float64(wma.values.size) * value
+	wma.sum += value
+}
+
+//Ready checks if the average has a full period of values
+func (wma *WMA) Ready() bool {
+	return wma.values.full()
+}
+
+//Value is the weighted average of the latest values
+func (wma *WMA) Value() float64 {
+	size := float64(wma.values.size)
+	if size == 0 {
+		return 0
+	}
+	return wma.weighted / (size * (size + 1) / 2)
+}
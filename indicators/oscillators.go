@@ -0,0 +1,132 @@
+package indicators
+
+import "math"
+
+//RSI is the relative strength index using the Wilder smoothing of the gains and losses
+type RSI struct {
+	gains, losses *EMA
+	previous      float64
+	started       bool
+}
+
+//MACD is the moving average convergence divergence of the close prices
+type MACD struct {
+	fast, slow, signal *EMA
+}
+
+//Stochastic is the stochastic oscillator, %K is the position of the close in the range of the period
+//and %D is the simple moving average of %K
+type Stochastic struct {
+	ranges *extremes
+	d      *SMA
+	k      float64
+}
+
+//NewRSI creates a relative strength index of the period, usually 14 candles
+func NewRSI(length int) *RSI {
+	return &RSI{gains: newWilderEMA(length), losses: newWilderEMA(length)}
+}
+
+//Update adds the close price of the candle
+func (rsi *RSI) Update(candle Candle) {
+	rsi.Add(candle.Close())
+}
+
+//Add adds a value to the index
+func (rsi *RSI) Add(value float64) {
+	if rsi.started {
+		change := value - rsi.previous
+		rsi.gains.Add(math.Max(change, 0))
+		rsi.losses.Add(math.Max(-change, 0))
+	}
+	rsi.previous, rsi.started = value, true
+}
+
+//Ready checks if the index received the changes of a full period
+func (rsi *RSI) Ready() bool {
+	return rsi.gains.Ready()
+}
+
+//Value is the index between 0 and 100
+func (rsi *RSI) Value() float64 {
+	if rsi.losses.Value() == 0 {
+		if rsi.gains.Value() == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+rsi.gains.Value()/rsi.losses.Value())
+}
+
+//NewMACD creates a MACD with the fast, slow and signal periods, usually 12, 26 and 9 candles
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+//Update adds the close price of the candle
+func (macd *MACD) Update(candle Candle) {
+	macd.Add(candle.Close())
+}
+
+//Add adds a value to the averages
+func (macd *MACD) Add(value float64) {
+	macd.fast.Add(value)
+	macd.slow.Add(value)
+	if macd.slow.Ready() && macd.fast.Ready() {
+		macd.signal.Add(macd.MACD())
+	}
+}
+
+//Ready checks if the signal line received a full period of values
+func (macd *MACD) Ready() bool {
+	return macd.signal.Ready()
+}
+
+//MACD is the difference between the fast and slow averages
+func (macd *MACD) MACD() float64 {
+	return macd.fast.Value() - macd.slow.Value()
+}
+
+//Signal is the exponential moving average of the MACD
+func (macd *MACD) Signal() float64 {
+	return macd.signal.Value()
+}
+
+//Histogram is the difference between the MACD and its signal
+func (macd *MACD) Histogram() float64 {
+	return macd.MACD() - macd.Signal()
+}
+
+//NewStochastic creates a stochastic oscillator with the %K and %D periods, usually 14 and 3 candles
+func NewStochastic(kLength, dLength int) *Stochastic {
+	return &Stochastic{ranges: newExtremes(kLength), d: NewSMA(dLength)}
+}
+
+//Update adds the candle to the oscillator
+func (stochastic *Stochastic) Update(candle Candle) {
+	stochastic.ranges.push(candle.High(), candle.Low())
+	if !stochastic.ranges.full() {
+		return
+	}
+
+	stochastic.k = 50
+	if highest, lowest := stochastic.ranges.highest(), stochastic.ranges.lowest(); highest > lowest {
+		stochastic.k = 100 * (candle.Close() - lowest) / (highest - lowest)
+	}
+	stochastic.d.Add(stochastic.k)
+}
+
+//Ready checks if %D received a full period of %K values
+func (stochastic *Stochastic) Ready() bool {
+	return stochastic.d.Ready()
+}
+
+//K is the position of the latest close between the lowest (0) and highest (100) prices of the period
+func (stochastic *Stochastic) K() float64 {
+	return stochastic.k
+}
+
+//D is the simple moving average of %K
+func (stochastic *Stochastic) D() float64 {
+	return stochastic.d.Value()
+}
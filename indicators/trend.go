@@ -0,0 +1,129 @@
+package indicators
+
+import "math"
+
+//ADX is the average directional index measuring the strength of the trend with the Wilder smoothing
+type ADX struct {
+	plusDM, minusDM, trueRange *EMA
+	adx                        *EMA
+	previous                   Candle
+}
+
+//Ichimoku is the Ichimoku Kinko Hyo. The leading spans returned are the ones projected to the latest candle,
+//calculated displacement candles ago, so no future prices are used.
+type Ichimoku struct {
+	conversion, base, span *extremes
+	spanA, spanB           *window
+	displacement           int
+}
+
+//NewADX creates a average directional index of the period, usually 14 candles
+func NewADX(length int) *ADX {
+	return &ADX{plusDM: newWilderEMA(length), minusDM: newWilderEMA(length), trueRange: newWilderEMA(length),
+		adx: newWilderEMA(length)}
+}
+
+//Update adds the directional movement of the candle
+func (adx *ADX) Update(candle Candle) {
+	previous := adx.previous
+	adx.previous = candle
+	if previous == nil {
+		return
+	}
+
+	up, down := candle.High()-previous.High(), previous.Low()-candle.Low()
+	plus, minus := 0.0, 0.0
+	if up > down && up > 0 {
+		plus = up
+	}
+	if down > up && down > 0 {
+		minus = down
+	}
+	trueRange := math.Max(candle.High()-candle.Low(), math.Max(math.Abs(candle.High()-previous.Close()),
+		math.Abs(candle.Low()-previous.Close())))
+
+	adx.plusDM.Add(plus)
+	adx.minusDM.Add(minus)
+	adx.trueRange.Add(trueRange)
+	if adx.trueRange.Ready() {
+		adx.adx.Add(adx.DX())
+	}
+}
+
+//Ready checks if the index received a full period of directional indexes
+func (adx *ADX) Ready() bool {
+	return adx.adx.Ready()
+}
+
+//Value is the average directional index between 0 and 100
+func (adx *ADX) Value() float64 {
+	return adx.adx.Value()
+}
+
+//PlusDI is the positive directional indicator
+func (adx *ADX) PlusDI() float64 {
+	if adx.trueRange.Value() == 0 {
+		return 0
+	}
+	return 100 * adx.plusDM.Value() / adx.trueRange.Value()
+}
+
+//MinusDI is the negative directional indicator
+func (adx *ADX) MinusDI() float64 {
+	if adx.trueRange.Value() == 0 {
+		return 0
+	}
+	return 100 * adx.minusDM.Value() / adx.trueRange.Value()
+}
+
+//DX is the directional index of the latest candle
+func (adx *ADX) DX() float64 {
+	plus, minus := adx.PlusDI(), adx.MinusDI()
+	if plus+minus == 0 {
+		return 0
+	}
+	return 100 * math.Abs(plus-minus) / (plus + minus)
+}
+
+//NewIchimoku creates a Ichimoku with the conversion, base and leading span B periods, usually 9, 26 and 52
+//candles. The leading spans are displaced by the base period.
+func NewIchimoku(conversion, base, spanB int) *Ichimoku {
+	return &Ichimoku{conversion: newExtremes(conversion), base: newExtremes(base), span: newExtremes(spanB),
+		spanA: newWindow(base + 1), spanB: newWindow(base + 1), displacement: period(base)}
+}
+
+//Update adds the high and low prices of the candle
+func (ichimoku *Ichimoku) Update(candle Candle) {
+	ichimoku.conversion.push(candle.High(), candle.Low())
+	ichimoku.base.push(candle.High(), candle.Low())
+	ichimoku.span.push(candle.High(), candle.Low())
+	if ichimoku.span.full() {
+		ichimoku.spanA.push((ichimoku.Conversion() + ichimoku.Base()) / 2)
+		ichimoku.spanB.push((ichimoku.span.highest() + ichimoku.span.lowest()) / 2)
+	}
+}
+
+//Ready checks if the leading spans projected to the latest candle are available
+func (ichimoku *Ichimoku) Ready() bool {
+	return ichimoku.spanB.full()
+}
+
+//Conversion is the conversion line (Tenkan-sen), the middle of the range of the conversion period
+func (ichimoku *Ichimoku) Conversion() float64 {
+	return (ichimoku.conversion.highest() + ichimoku.conversion.lowest()) / 2
+}
+
+//Base is the base line (Kijun-sen), the middle of the range of the base period
+func (ichimoku *Ichimoku) Base() float64 {
+	return (ichimoku.base.highest() + ichimoku.base.lowest()) / 2
+}
+
+//SpanA is the leading span A (Senkou Span A) for the latest candle
+func (ichimoku *Ichimoku) SpanA() float64 {
+	return ichimoku.spanA.oldest()
+}
+
+//SpanB is the leading span B (Senkou Span B) for the latest candle
+func (ichimoku *Ichimoku) SpanB() float64 {
+	return ichimoku.spanB.oldest()
+}
@@ -0,0 +1,237 @@
+package indicators
+
+import "math"
+
+//Bollinger are the Bollinger Bands, a simple moving average surrounded by bands a multiple of the
+//standard deviation away from it
+type Bollinger struct {
+	values     *window
+	sum        float64
+	squares    float64
+	multiplier float64
+}
+
+//ATR is the average true range using the Wilder smoothing
+type ATR struct {
+	average   *EMA
+	trueRange float64
+	previous  float64
+	started   bool
+}
+
+//Keltner are the Keltner Channels, a exponential moving average surrounded by bands a multiple of the ATR away from it
+type Keltner struct {
+	middle     *EMA
+	atr        *ATR
+	multiplier float64
+}
+
+//Donchian are the Donchian Channels with the highest and lowest prices of the period
+type Donchian struct {
+	ranges *extremes
+}
+
+//SuperTrend follows the trend with a band a multiple of the ATR away from the median price,
+//the band only moves in the direction of the trend and flips when the close crosses it
+type SuperTrend struct {
+	atr          *ATR
+	multiplier   float64
+	upper, lower float64
+	previous     float64
+	uptrend      bool
+	started      bool
+}
+
+//NewBollinger creates Bollinger Bands of the period with bands the multiple of standard deviations away,
+//usually 20 candles and 2 standard deviations
+func NewBollinger(length int, multiplier float64) *Bollinger {
+	return &Bollinger{values: newWindow(length), multiplier: multiplier}
+}
+
+//Update adds the close price of the candle
+func (bollinger *Bollinger) Update(candle Candle) {
+	bollinger.Add(candle.Close())
+}
+
+//Add adds a value to the bands
+func (bollinger *Bollinger) Add(value float64) {
+	removed, _ := bollinger.values.push(value)
+	bollinger.sum += value - removed
+	bollinger.squares += value*value - removed*removed
+}
+
+//Ready checks if the bands have a full period of values
+func (bollinger *Bollinger) Ready() bool {
+	return bollinger.values.full()
+}
+
+//Middle is the simple moving average of the period
+func (bollinger *Bollinger) Middle() float64 {
+	if bollinger.values.size == 0 {
+		return 0
+	}
+	return bollinger.sum / float64(bollinger.values.size)
+}
+
+//StdDev is the population standard deviation of the values of the period
+func (bollinger *Bollinger) StdDev() float64 {
+	if bollinger.values.size == 0 {
+		return 0
+	}
+	mean := bollinger.Middle()
+	return math.Sqrt(math.Max(bollinger.squares/float64(bollinger.values.size)-mean*mean, 0))
+}
+
+//Upper is the band above the average
+func (bollinger *Bollinger) Upper() float64 {
+	return bollinger.Middle() + bollinger.multiplier*bollinger.StdDev()
+}
+
+//Lower is the band below the average
+func (bollinger *Bollinger) Lower() float64 {
+	return bollinger.Middle() - bollinger.multiplier*bollinger.StdDev()
+}
+
+//NewATR creates a average true range of the period, usually 14 candles
+func NewATR(length int) *ATR {
+	return &ATR{average: newWilderEMA(length)}
+}
+
+//Update adds the true range of the candle
+func (atr *ATR) Update(candle Candle) {
+	atr.trueRange = candle.High() - candle.Low()
+	if atr.started {
+		atr.trueRange = math.Max(atr.trueRange, math.Max(math.Abs(candle.High()-atr.previous),
+			math.Abs(candle.Low()-atr.previous)))
+	}
+	atr.previous, atr.started = candle.Close(), true
+	atr.average.Add(atr.trueRange)
+}
+
+//Ready checks if the average received a full period of true ranges
+func (atr *ATR) Ready() bool {
+	return atr.average.Ready()
+}
+
+//Value is the average true range
+func (atr *ATR) Value() float64 {
+	return atr.average.Value()
+}
+
+//TrueRange is the true range of the latest candle
+func (atr *ATR) TrueRange() float64 {
+	return atr.trueRange
+}
+
+//NewKeltner creates Keltner Channels with the periods of the average and ATR and the multiple of the ATR,
+//usually 20 candles, 10 candles and 2
+func NewKeltner(length, atrLength int, multiplier float64) *Keltner {
+	return &Keltner{middle: NewEMA(length), atr: NewATR(atrLength), multiplier: multiplier}
+}
+
+//Update adds the candle to the average and ATR
+func (keltner *Keltner) Update(candle Candle) {
+	keltner.middle.Update(candle)
+	keltner.atr.Update(candle)
+}
+
+//Ready checks if both the average and the ATR completed the warm-up
+func (keltner *Keltner) Ready() bool {
+	return keltner.middle.Ready() && keltner.atr.Ready()
+}
+
+//Middle is the exponential moving average of the close prices
+func (keltner *Keltner) Middle() float64 {
+	return keltner.middle.Value()
+}
+
+//Upper is the channel above the average
+func (keltner *Keltner) Upper() float64 {
+	return keltner.middle.Value() + keltner.multiplier*keltner.atr.Value()
+}
+
+//Lower is the channel below the average
+func (keltner *Keltner) Lower() float64 {
+	return keltner.middle.Value() - keltner.multiplier*keltner.atr.Value()
+}
+
+//NewDonchian creates Donchian Channels of the period, usually 20 candles
+func NewDonchian(length int) *Donchian {
+	return &Donchian{ranges: newExtremes(length)}
+}
+
+//Update adds the high and low prices of the candle
+func (donchian *Donchian) Update(candle Candle) {
+	donchian.ranges.push(candle.High(), candle.Low())
+}
+
+//Ready checks if the channels have a full period of candles
+func (donchian *Donchian) Ready() bool {
+	return donchian.ranges.full()
+}
+
+//Upper is the highest price of the period
+func (donchian *Donchian) Upper() float64 {
+	return donchian.ranges.highest()
+}
+
+//Lower is the lowest price of the period
+func (donchian *Donchian) Lower() float64 {
+	return donchian.ranges.lowest()
+}
+
+//Middle is the average of the upper and lower channels
+func (donchian *Donchian) Middle() float64 {
+	return (donchian.Upper() + donchian.Lower()) / 2
+}
+
+//NewSuperTrend creates a SuperTrend with the ATR period and multiple, usually 10 candles and 3
+func NewSuperTrend(atrLength int, multiplier float64) *SuperTrend {
+	return &SuperTrend{atr: NewATR(atrLength), multiplier: multiplier, uptrend: true}
+}
+
+//Update adds the candle moving the bands in the direction of the trend
+func (superTrend *SuperTrend) Update(candle Candle) {
+	superTrend.atr.Update(candle)
+	if !superTrend.atr.Ready() {
+		superTrend.previous = candle.Close()
+		return
+	}
+
+	median := (candle.High() + candle.Low()) / 2
+	upper := median + superTrend.multiplier*superTrend.atr.Value()
+	lower := median - superTrend.multiplier*superTrend.atr.Value()
+	if superTrend.started {
+		if upper > superTrend.upper && superTrend.previous <= superTrend.upper {
+			upper = superTrend.upper
+		}
+		if lower < superTrend.lower && superTrend.previous >= superTrend.lower {
+			lower = superTrend.lower
+		}
+		if superTrend.uptrend && candle.Close() < lower {
+			superTrend.uptrend = false
+		} else if !superTrend.uptrend && candle.Close() > upper {
+			superTrend.uptrend = true
+		}
+	}
+	superTrend.upper, superTrend.lower = upper, lower
+	superTrend.previous, superTrend.started = candle.Close(), true
+}
+
+//Ready checks if the ATR completed the warm-up
+func (superTrend *SuperTrend) Ready() bool {
+	return superTrend.started
+}
+
+//Value is the band followed by the trend: the lower band in uptrends and the upper band in downtrends
+func (superTrend *SuperTrend) Value() float64 {
+	if superTrend.uptrend {
+		return superTrend.lower
+	}
+	return superTrend.upper
+}
+
+//Uptrend checks if the close is above the SuperTrend
+func (superTrend *SuperTrend) Uptrend() bool {
+	return superTrend.uptrend
+}
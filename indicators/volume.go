@@ -0,0 +1,74 @@
+package indicators
+
+//OBV is the on-balance volume, the cumulative volume added on rising closes and subtracted on falling closes
+type OBV struct {
+	value    float64
+	previous float64
+	started  bool
+}
+
+//VWAP is the volume weighted average price of the typical price (high + low + close) / 3.
+//When the candles have timestamps the average restarts on each UTC day.
+type VWAP struct {
+	weighted float64
+	volume   float64
+	day      int
+	year     int
+}
+
+//NewOBV creates a on-balance volume
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+//Update adds the volume of the candle
+func (obv *OBV) Update(candle Candle) {
+	if obv.started {
+		if candle.Close() > obv.previous {
+			obv.value += candle.Volume()
+		} else if candle.Close() < obv.previous {
+			obv.value -= candle.Volume()
+		}
+	}
+	obv.previous, obv.started = candle.Close(), true
+}
+
+//Ready checks if a candle was received
+func (obv *OBV) Ready() bool {
+	return obv.started
+}
+
+//Value is the cumulative volume
+func (obv *OBV) Value() float64 {
+	return obv.value
+}
+
+//NewVWAP creates a volume weighted average price
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+//Update adds the typical price of the candle weighted by its volume
+func (vwap *VWAP) Update(candle Candle) {
+	if closeTime := candle.Time().UTC(); !closeTime.IsZero() {
+		if closeTime.YearDay() != vwap.day || closeTime.Year() != vwap.year {
+			vwap.weighted, vwap.volume = 0, 0
+			vwap.day, vwap.year = closeTime.YearDay(), closeTime.Year()
+		}
+	}
+	vwap.weighted += (candle.High() + candle.Low() + candle.Close()) / 3 * candle.Volume()
+	vwap.volume += candle.Volume()
+}
+
+//Ready checks if any volume was traded
+func (vwap *VWAP) Ready() bool {
+	return vwap.volume > 0
+}
+
+//Value is the volume weighted average price
+func (vwap *VWAP) Value() float64 {
+	if vwap.volume == 0 {
+		return 0
+	}
+	return vwap.weighted / vwap.volume
+}
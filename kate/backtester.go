@@ -1,11 +1,47 @@
 package kate
 
-//Backtester allows backtesting trading strategies on crypto markets
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+//Backtester allows backtesting trading strategies on crypto markets.
+//The backtester only holds the configuration of the simulation, every call to Run starts from the same initial state.
 type Backtester struct {
+	newStrategy     func() Strategy
+	exchangeHandler *ExchangeHandler //configuration of the exchange, copied at the start of every run
+	dataHandler     *DataHandler
+	timeframes      []timeframeFeed
+	lookback        int
+	sizer           PositionSizer
+	riskManager     RiskManager
+	closeAtEnd      bool  //closes the position still open at the end of the run at the latest price
+	shared          bool  //the same strategy instance is used by every run, so the runs can't be concurrent
+	running         int32 //set while a run of a shared strategy is in progress
+}
+
+//timeframeFeed is a higher timeframe of the price data and the index of the next candle to deliver
+type timeframeFeed struct {
+	name string
+	data *DataHandler
+	next int
+}
+
+//simulation is the state of a single run of a Backtester
+type simulation struct {
 	eventQueue      EventQueue
 	myStrategy      Strategy
 	exchangeHandler *ExchangeHandler
 	dataHandler     *DataHandler
+	equityCurve     []EquityPoint
+	timeframes      []timeframeFeed
+	history         *Series
+	lookback        int
+	sizer           PositionSizer
+	riskManager     RiskManager
+	risk            riskState
+	closeAtEnd      bool
 }
 
 //BacktestOptions is general settings for running a backtest
@@ -14,29 +50,46 @@ type BacktestOptions struct {
 	Market             MarketType
 	MakerFeePercentage float64
 	TakerFeePercentage float64
-	percentagePerTrade float64
+	PercentagePerTrade float64 //percentage of the balance used to open each position, zero uses 1%
 }
 
 //Event represents a action that will be processed by the eventloop
 type Event interface{}
 
-//NewBacktester creates a new backtester instance that allows running trading simulations on crypto markets
+//NewBacktester creates a new backtester instance that allows running trading simulations on crypto markets.
+//The same strategy instance is used by every run: strategies that keep state between candles must reset it in
+//OnStart (StartListener) for the runs to be repeatable, and Run panics when it is called while another run is in
+//progress. Use NewBacktesterFromFactory to run the backtester concurrently.
 func NewBacktester(mystrategy Strategy, dataHandler *DataHandler) *Backtester {
+	backtester := NewBacktesterFromFactory(func() Strategy { return mystrategy }, dataHandler)
+	backtester.shared = true
+	return backtester
+}
+
+//NewBacktesterFromFactory creates a new backtester that creates a new strategy instance for every run,
+//allowing the backtester to be run concurrently
+func NewBacktesterFromFactory(newStrategy func() Strategy, dataHandler *DataHandler) *Backtester {
 	return &Backtester{
 		exchangeHandler: NewExchangeHandler(USDFutures, 0.02, 0.04, 1),
 		dataHandler:     dataHandler,
-		myStrategy:      mystrategy,
+		newStrategy:     newStrategy,
 	}
 }
 
-//NewCustomizedBacktester creates a new customized backtester instance that allows running trading simulations on crypto markets
+//NewCustomizedBacktester creates a new customized backtester instance that allows running trading simulations on crypto markets.
+//The strategy instance is shared by every run like on NewBacktester.
 func NewCustomizedBacktester(mystrategy Strategy, dataHandler *DataHandler, options BacktestOptions) *Backtester {
+	percentagePerTrade := options.PercentagePerTrade
+	if percentagePerTrade <= 0 {
+		percentagePerTrade = 1
+	}
 	exchangeHandler := NewExchangeHandler(options.Market, options.MakerFeePercentage, options.TakerFeePercentage,
-		options.percentagePerTrade)
+		percentagePerTrade)
 	return &Backtester{
 		exchangeHandler: exchangeHandler,
 		dataHandler:     dataHandler,
-		myStrategy:      mystrategy,
+		newStrategy:     func() Strategy { return mystrategy },
+		shared:          true,
 	}
 }
 
@@ -45,54 +98,215 @@ func (bt *Backtester) SetBalance(amount float64) {
 	bt.exchangeHandler.balance = amount
 }
 
-//SetFixedTradeAmount defines a fixed value that will be used to open every position when trading
+//SetFixedTradeAmount defines a fixed value that will be used to open every position when trading,
+//the positions are rejected while the amount exceeds the balance
 func (bt *Backtester) SetFixedTradeAmount(amount float64) {
 	bt.exchangeHandler.fixedTradeAmount = amount
 }
 
-//Run executes a trading simulation for the provided configuration on the Backtester
+//SetPositionSizer defines the model that decides the amount of the balance used to open each position
+func (bt *Backtester) SetPositionSizer(sizer PositionSizer) {
+	bt.sizer = sizer
+}
+
+//SetRiskManager defines the risk manager that reviews every position requested by the strategy,
+//the positions vetoed or resized are recorded in the RiskInterventions of the results
+func (bt *Backtester) SetRiskManager(manager RiskManager) {
+	bt.riskManager = manager
+}
+
+//SetLookback defines the amount of candles kept in the history delivered to strategies implementing
+//HistoryStrategy, no positions are opened until the history has the lookback candles
+func (bt *Backtester) SetLookback(candles int) {
+	bt.lookback = candles
+}
+
+//AddTimeframe adds a higher timeframe of the same symbol delivered to strategies implementing TimeframeStrategy.
+//The timeframe can be loaded separately or derived from the price data with DataHandler.Resample,
+//both feeds require timestamps to deliver the candles only after they are closed.
+func (bt *Backtester) AddTimeframe(name string, data *DataHandler) error {
+	if !bt.dataHandler.hasTimestamps() || !data.hasTimestamps() {
+		return fmt.Errorf("the timeframe %v requires price data with timestamps", name)
+	}
+	for _, timeframe := range bt.timeframes {
+		if timeframe.name == name {
+			return fmt.Errorf("the timeframe %v was already added", name)
+		}
+	}
+	bt.timeframes = append(bt.timeframes, timeframeFeed{name: name, data: data})
+	return nil
+}
+
+//Run executes a trading simulation for the provided configuration on the Backtester.
+//Every run starts with a fresh exchange state, runs sharing the same DataHandler can be executed concurrently.
 func (bt *Backtester) Run() *Statistics {
-	initialBalance := bt.exchangeHandler.balance
+	if bt.shared {
+		if !atomic.CompareAndSwapInt32(&bt.running, 0, 1) {
+			panic("the strategy instance of the backtester is already running, use NewBacktesterFromFactory " +
+				"for concurrent runs")
+		}
+		defer atomic.StoreInt32(&bt.running, 0)
+	}
+	sim := &simulation{
+		myStrategy:      forRun(bt.newStrategy()),
+		exchangeHandler: bt.exchangeHandler.reset(),
+		dataHandler:     bt.dataHandler,
+		timeframes:      append([]timeframeFeed{}, bt.timeframes...),
+		history:         NewSeries(defaultHistory),
+		lookback:        bt.lookback,
+		sizer:           bt.sizer,
+		riskManager:     bt.riskManager,
+		closeAtEnd:      bt.closeAtEnd,
+	}
+	if bt.lookback > 0 {
+		sim.history = NewSeries(bt.lookback)
+	}
+	return sim.run()
+}
 
-	for _, candle := range bt.dataHandler.Prices {
-		for bt.eventQueue.HasNext() {
-			bt.processNextEvent()
+func (sim *simulation) run() *Statistics {
+	initialBalance := sim.exchangeHandler.balance
+	sim.risk = riskState{peakEquity: initialBalance, dayStartEquity: initialBalance}
+	if strategy, ok := sim.listener().(HistoryStrategy); ok {
+		strategy.SetHistory(sim.history)
+	}
+	if strategy, ok := sim.myStrategy.(contextStrategy); ok {
+		strategy.setContext(&Context{sim: sim})
+	}
+	sim.registerHooks()
+	if listener, ok := sim.listener().(StartListener); ok {
+		listener.OnStart(sim.config())
+	}
+
+	for _, candle := range sim.dataHandler.Prices {
+		for sim.eventQueue.HasNext() {
+			sim.processNextEvent()
 		}
-		bt.eventQueue.AddEvent(candle)
+		sim.eventQueue.AddEvent(candle)
+	}
+	if sim.closeAtEnd {
+		sim.closeOpenPosition()
 	}
 
-	return bt.calculateStatistics(initialBalance)
+	stats := sim.calculateStatistics(initialBalance)
+	if listener, ok := sim.listener().(EndListener); ok {
+		listener.OnEnd(stats)
+	}
+	return stats
+}
+
+//closeOpenPosition processes the last candle still queued and closes the position open at its price, the last point
+//of the equity curve is updated with the balance after the close. The positions requested on the last candle are not
+//opened as no prices are left to trade them.
+func (sim *simulation) closeOpenPosition() {
+	for sim.eventQueue.HasNext() {
+		if _, open := sim.eventQueue.events[0].(*OpenPositionEvt); open {
+			sim.eventQueue.NextEvent()
+			continue
+		}
+		sim.processNextEvent()
+	}
+	if sim.exchangeHandler.openPosition == nil {
+		return
+	}
+	sim.exchangeHandler.closeMarketOrder(EndOfDataExit)
+	if last := len(sim.equityCurve) - 1; last >= 0 {
+		sim.equityCurve[last].Balance = sim.exchangeHandler.balance
+		sim.equityCurve[last].Equity = sim.exchangeHandler.equity()
+	}
 }
 
 //processNextEvent process the next event in the queue if the queue is not empty.
-func (bt *Backtester) processNextEvent() {
-	switch event := bt.eventQueue.NextEvent().(type) {
+func (sim *simulation) processNextEvent() {
+	switch event := sim.eventQueue.NextEvent().(type) {
 	case DataPoint:
-		bt.processNewPriceEvt(event)
+		sim.processNewPriceEvt(event)
 	case *OpenPositionEvt:
-		bt.exchangeHandler.OpenMarketOrder(event.Direction, event.Leverage)
+		sim.openPosition(event)
 	case *StoplossEvt:
-		bt.exchangeHandler.SetStoploss(event.Price)
+		sim.exchangeHandler.SetStoploss(event.Price)
 	case *TakeProfitEvt:
-		bt.exchangeHandler.SetTakeProfit(event.Price)
+		sim.exchangeHandler.SetTakeProfit(event.Price)
+	case *ClosePositionEvt:
+		sim.exchangeHandler.CloseMarketOrder()
 	}
 }
 
-func (bt *Backtester) processNewPriceEvt(newPrice DataPoint) {
-	bt.exchangeHandler.onPriceChange(newPrice)
-	bt.myStrategy.PreProcessIndicators(newPrice)
+//openPosition sends the position requested by the strategy to the exchange once approved by the risk manager
+func (sim *simulation) openPosition(event *OpenPositionEvt) {
+	leverage, amount := event.Leverage, sim.tradeAmount(event)
+	if sim.riskManager != nil {
+		var approved bool
+		if leverage, amount, approved = sim.reviewRisk(event, leverage, amount); !approved {
+			return
+		}
+	}
 
-	if bt.exchangeHandler.openPosition == nil {
-		if evt := bt.myStrategy.OpenNewPosition(newPrice); evt != nil {
-			bt.eventQueue.AddEvent(evt)
+	if sim.exchangeHandler.openMarketOrder(event.Direction, leverage, amount) != nil {
+		return
+	}
+	sim.risk.tradesToday++
+	if event.Stoploss > 0 {
+		sim.exchangeHandler.SetStoploss(event.Stoploss)
+	}
+	if event.TakeProfit > 0 {
+		sim.exchangeHandler.SetTakeProfit(event.TakeProfit)
+	}
+}
+
+func (sim *simulation) processNewPriceEvt(newPrice DataPoint) {
+	previousTime := sim.exchangeHandler.currentTime
+	sim.exchangeHandler.onPriceChange(newPrice)
+	sim.equityCurve = append(sim.equityCurve, EquityPoint{
+		Candle:  sim.exchangeHandler.currentCandle,
+		Time:    newPrice.Time(),
+		Balance: sim.exchangeHandler.balance,
+		Equity:  sim.exchangeHandler.equity(),
+	})
+	sim.risk.updateEquity(sim.exchangeHandler.equity())
+	sim.history.Add(newPrice)
+	sim.checkDayChange(previousTime, newPrice.Time())
+	sim.deliverTimeframes(newPrice)
+	sim.myStrategy.PreProcessIndicators(newPrice)
+
+	openPosition := sim.exchangeHandler.openPosition
+	if strategy, ok := sim.listener().(ExitStrategy); ok && openPosition != nil && strategy.ClosePosition(*openPosition) {
+		sim.eventQueue.AddEvent(&ClosePositionEvt{})
+		openPosition = nil
+	}
+
+	if openPosition == nil {
+		if sim.lookback > 0 && !sim.history.Full() {
+			return
+		}
+		if evt := sim.myStrategy.OpenNewPosition(newPrice); evt != nil {
+			sim.eventQueue.AddEvent(evt)
 		}
 	} else {
-		if evt := bt.myStrategy.SetStoploss(*bt.exchangeHandler.openPosition); evt != nil {
-			bt.eventQueue.AddEvent(evt)
+		if evt := sim.myStrategy.SetStoploss(*openPosition); evt != nil {
+			sim.eventQueue.AddEvent(evt)
 		}
 
-		if evt := bt.myStrategy.SetTakeProfit(*bt.exchangeHandler.openPosition); evt != nil {
-			bt.eventQueue.AddEvent(evt)
+		if evt := sim.myStrategy.SetTakeProfit(*openPosition); evt != nil {
+			sim.eventQueue.AddEvent(evt)
+		}
+	}
+}
+
+//deliverTimeframes sends the candles of the higher timeframes closed until the new price to the strategy
+func (sim *simulation) deliverTimeframes(newPrice DataPoint) {
+	strategy, ok := sim.listener().(TimeframeStrategy)
+	if !ok {
+		return
+	}
+	for i := range sim.timeframes {
+		timeframe := &sim.timeframes[i]
+		for ; timeframe.next < len(timeframe.data.Prices); timeframe.next++ {
+			candle := timeframe.data.Prices[timeframe.next]
+			if candle.Time().After(newPrice.Time()) {
+				break
+			}
+			strategy.PreProcessTimeframe(timeframe.name, candle)
 		}
 	}
 }
@@ -101,3 +315,8 @@ func (bt *Backtester) processNewPriceEvt(newPrice DataPoint) {
 func (bt *Backtester) SetSlippagePercentage(slippagePercent float64) {
 	bt.exchangeHandler.SetSlipage(slippagePercent)
 }
+
+//SetFundingRate defines the funding rate charged from open positions on every interval (usually 8 hours)
+func (bt *Backtester) SetFundingRate(ratePercent float64, interval time.Duration) {
+	bt.exchangeHandler.SetFundingRate(ratePercent, interval)
+}
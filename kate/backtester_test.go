@@ -1,6 +1,7 @@
 package kate
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -27,7 +28,8 @@ func TestSimpleTradingStrategyRun(t *testing.T) {
 		{"../testdata/ETHUSD4.csv", 7, 300, &Statistics{TotalDataPoints: 21265, TotalTrades: 133, MaxDrawdown: 0.06771563065859225, WinRate: 0.5338345864661654,
 			SharpeRatio: -1.5822095979921864, NetProfit: -9.900870000000737, ROIPercentage: -3.300290000000246}},
 		{"../testdata/ETHUSD5.csv", 10, 1000, &Statistics{TotalDataPoints: 43200, TotalTrades: 856, MaxDrawdown: 0.21341551110001716, WinRate: 0.49182242990654207,
-			SharpeRatio: -3.2570713833447655, NetProfit: -208.15910000001497, ROIPercentage: -20.815910000001498}},
+			SharpeRatio: -3.2570713833447655, NetProfit: -208.15910000001497, ROIPercentage: -20.815910000001498,
+			Liquidations: 1, LiquidationLoss: 8.853199999999987}},
 		{"../testdata/mockdata.csv", 5, 1000, &Statistics{TotalDataPoints: 22, TotalTrades: 4, WinRate: 0.75, MaxDrawdown: 0.0008679817866541949,
 			ROIPercentage: 0.11098500000000514, NetProfit: 1.1098500000000513, SharpeRatio: 0.003966684810396764}},
 	}
@@ -54,10 +56,106 @@ func TestSimpleTradingStrategyRun(t *testing.T) {
 
 }
 
+func TestRepeatableConcurrentRuns(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	backtester := NewBacktesterFromFactory(func() Strategy { return newSimpleStrategy() }, data)
+	backtester.SetBalance(1000)
+	backtester.SetFixedTradeAmount(20)
+	expected := backtester.Run()
+	if expected.TotalTrades != 21 {
+		t.Fatalf("Expected 21 trades, found %v", expected.TotalTrades)
+	}
+
+	results := make([]*Statistics, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = backtester.Run()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if diff := deep.Equal(result, expected); diff != nil {
+			t.Errorf("Run %v differs from the first run: %v", i, diff)
+		}
+		if len(result.Trades()) != expected.TotalTrades || len(result.EquityCurve()) != len(expected.EquityCurve()) {
+			t.Errorf("Run %v kept state from previous runs", i)
+		}
+	}
+}
+
+func TestRepeatableSharedRuns(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	backtester := NewBacktester(newSimpleStrategy(), data)
+	backtester.SetBalance(1000)
+	backtester.SetFixedTradeAmount(20)
+	first, second := backtester.Run(), backtester.Run()
+	if first.TotalTrades != 21 {
+		t.Fatalf("Expected 21 trades, found %v", first.TotalTrades)
+	}
+	if diff := deep.Equal(first, second); diff != nil {
+		t.Errorf("The second run of the same strategy instance differs from the first: %v", diff)
+	}
+}
+
+//reentrantStrategy runs its own backtester again in the middle of the run, recording the panic of the run rejected
+type reentrantStrategy struct {
+	simpleStrategy
+	backtester *Backtester
+	rejected   interface{}
+}
+
+func (strategy *reentrantStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	if backtester := strategy.backtester; backtester != nil {
+		strategy.backtester = nil
+		func() {
+			defer func() { strategy.rejected = recover() }()
+			backtester.Run()
+		}()
+	}
+	strategy.simpleStrategy.PreProcessIndicators(latestPrice)
+}
+
+func TestSharedStrategyRejectsConcurrentRuns(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy := &reentrantStrategy{}
+	backtester := NewBacktester(strategy, data)
+	strategy.backtester = backtester
+	backtester.Run()
+	if strategy.rejected == nil {
+		t.Fatal("A run of the shared strategy should be rejected while another run is in progress")
+	}
+
+	strategy.backtester = nil
+	if stats := backtester.Run(); stats.TotalTrades == 0 {
+		t.Errorf("The backtester should run again once the previous run finished")
+	}
+}
+
 func newSimpleStrategy() *simpleStrategy {
 	return &simpleStrategy{}
 }
 
+//OnStart forgets the prices of the previous run
+func (strategy *simpleStrategy) OnStart(config RunConfig) {
+	strategy.lastPrice, strategy.currentPrice = nil, nil
+}
+
 //PreProcessIndicators nothing to do
 func (strategy *simpleStrategy) PreProcessIndicators(latestPrice DataPoint) {
 	strategy.lastPrice = strategy.currentPrice
@@ -0,0 +1,150 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//VolumeBars aggregates the candles in bars that close once the traded volume reaches the threshold.
+//The candles after the last complete bar are discarded.
+func (handler *DataHandler) VolumeBars(threshold float64) (*DataHandler, error) {
+	return handler.thresholdBars(threshold, func(candle DataPoint) float64 {
+		return candle.volume
+	})
+}
+
+//DollarBars aggregates the candles in bars that close once the traded value (close price * volume) reaches
+//the threshold. The candles after the last complete bar are discarded.
+func (handler *DataHandler) DollarBars(threshold float64) (*DataHandler, error) {
+	return handler.thresholdBars(threshold, func(candle DataPoint) float64 {
+		return candle.close * candle.volume
+	})
+}
+
+//RangeBars aggregates the candles in bars that close once the difference between the highest and lowest prices
+//reaches the size. The candles after the last complete bar are discarded.
+func (handler *DataHandler) RangeBars(size float64) (*DataHandler, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("the range of the bars must be positive, found %v", size)
+	}
+
+	var prices []DataPoint
+	var bar *DataPoint
+	for _, candle := range handler.Prices {
+		bar = aggregate(bar, candle)
+		if bar.high-bar.low >= size {
+			prices = append(prices, *bar)
+			bar = nil
+		}
+	}
+	return newDataHandler(prices), nil
+}
+
+//Renko builds bricks of a fixed size from the close prices, a new brick is added each time the close moves a
+//brick size beyond the last brick so reversals require a move of two bricks. The volume traded until a brick
+//is completed is assigned to the first brick formed on that candle.
+func (handler *DataHandler) Renko(brickSize float64) (*DataHandler, error) {
+	if brickSize <= 0 {
+		return nil, fmt.Errorf("the size of the bricks must be positive, found %v", brickSize)
+	}
+	if len(handler.Prices) == 0 {
+		return newDataHandler(nil), nil
+	}
+
+	var prices []DataPoint
+	top, bottom := handler.Prices[0].close, handler.Prices[0].close
+	volume := 0.0
+	for _, candle := range handler.Prices {
+		volume += candle.volume
+		for candle.close >= top+brickSize {
+			prices = append(prices, NewDataPoint(top, top+brickSize, top, top+brickSize, volume, candle.timestamp))
+			bottom, top, volume = top, top+brickSize, 0
+		}
+		for candle.close <= bottom-brickSize {
+			prices = append(prices, NewDataPoint(bottom, bottom, bottom-brickSize, bottom-brickSize, volume, candle.timestamp))
+			top, bottom, volume = bottom, bottom-brickSize, 0
+		}
+	}
+	return newDataHandler(prices), nil
+}
+
+//HeikinAshi transforms the candles in Heikin-Ashi candles that smooth the price action
+func (handler *DataHandler) HeikinAshi() *DataHandler {
+	prices := make([]DataPoint, len(handler.Prices))
+	for i, candle := range handler.Prices {
+		close := (candle.open + candle.high + candle.low + candle.close) / 4
+		open := (candle.open + candle.close) / 2
+		if i > 0 {
+			open = (prices[i-1].open + prices[i-1].close) / 2
+		}
+		prices[i] = NewDataPoint(open, math.Max(candle.high, math.Max(open, close)),
+			math.Min(candle.low, math.Min(open, close)), close, candle.volume, candle.timestamp)
+	}
+	return newDataHandler(prices)
+}
+
+//SaveCSV writes the candles in the OHLCV csv format read by PricesFromCSV,
+//the close_time column is included when the candles have timestamps
+func (handler *DataHandler) SaveCSV(path string) error {
+	columns := append([]string{}, csvColumns...)
+	hasTime := handler.hasTimestamps()
+	if hasTime {
+		columns = append(columns, csvTimeColumns[0])
+	}
+
+	rows := make([][]string, len(handler.Prices))
+	for i, candle := range handler.Prices {
+		rows[i] = []string{formatPrice(candle.open), formatPrice(candle.high), formatPrice(candle.low),
+			formatPrice(candle.close), formatPrice(candle.volume)}
+		if hasTime {
+			rows[i] = append(rows[i], unixTimestamp(candle.timestamp))
+		}
+	}
+	return writeCSV(path, columns, rows)
+}
+
+//thresholdBars closes a bar each time the sum of the measure of its candles reaches the threshold
+func (handler *DataHandler) thresholdBars(threshold float64, measure func(candle DataPoint) float64) (*DataHandler, error) {
+	if threshold <= 0 {
+		return nil, fmt.Errorf("the threshold of the bars must be positive, found %v", threshold)
+	}
+
+	var prices []DataPoint
+	var bar *DataPoint
+	total := 0.0
+	for _, candle := range handler.Prices {
+		bar = aggregate(bar, candle)
+		if total += measure(candle); total >= threshold {
+			prices = append(prices, *bar)
+			bar, total = nil, 0
+		}
+	}
+	return newDataHandler(prices), nil
+}
+
+//aggregate adds the candle to the bar, a nil bar starts a new bar with the candle
+func aggregate(bar *DataPoint, candle DataPoint) *DataPoint {
+	if bar == nil {
+		return &candle
+	}
+	bar.high = math.Max(bar.high, candle.high)
+	bar.low = math.Min(bar.low, candle.low)
+	bar.close = candle.close
+	bar.volume += candle.volume
+	bar.timestamp = candle.timestamp
+	return bar
+}
+
+func formatPrice(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+//unixTimestamp formats the time in seconds, or milliseconds when it is not a whole second
+func unixTimestamp(moment time.Time) string {
+	if moment.Nanosecond() == 0 {
+		return strconv.FormatInt(moment.Unix(), 10)
+	}
+	return strconv.FormatInt(moment.UnixNano()/int64(time.Millisecond), 10)
+}
@@ -0,0 +1,105 @@
+package kate
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAlternativeBars(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	volume, _ := data.VolumeBars(5e7)
+	dollar, _ := data.DollarBars(1e11)
+	ranges, _ := data.RangeBars(10)
+	tests := []struct {
+		name    string
+		bars    *DataHandler
+		measure func(candle DataPoint) float64
+		min     float64
+	}{
+		{"volume", volume, func(candle DataPoint) float64 { return candle.Volume() }, 5e7},
+		{"dollar", dollar, nil, 0}, //the value of each candle is not recoverable from the bar
+		{"range", ranges, func(candle DataPoint) float64 { return candle.High() - candle.Low() }, 10},
+	}
+	for _, test := range tests {
+		if len(test.bars.Prices) < 5 || len(test.bars.Prices) >= len(data.Prices) {
+			t.Fatalf("%v: unexpected amount of bars %v", test.name, len(test.bars.Prices))
+		}
+		for i, bar := range test.bars.Prices {
+			if test.measure != nil && test.measure(bar) < test.min {
+				t.Errorf("%v: the bar %v closed before reaching the threshold: %v", test.name, i, test.measure(bar))
+			}
+			if bar.High() < bar.Low() || bar.Close() > bar.High() || bar.Open() < bar.Low() {
+				t.Errorf("%v: the bar %v has invalid prices %+v", test.name, i, bar)
+			}
+		}
+	}
+
+	if _, err := data.VolumeBars(0); err == nil {
+		t.Errorf("A threshold of zero should be rejected")
+	}
+}
+
+func TestRenko(t *testing.T) {
+	closes := []float64{100, 101, 103.5, 102.5, 101, 100.9, 99, 104}
+	prices := make([]DataPoint, len(closes))
+	for i, close := range closes {
+		prices[i] = NewDataPoint(close, close, close, close, 1, time.Unix(int64(60*i), 0))
+	}
+
+	bricks, err := (&DataHandler{Prices: prices}).Renko(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opens, closesFound []float64
+	for _, brick := range bricks.Prices {
+		opens = append(opens, brick.Open())
+		closesFound = append(closesFound, brick.Close())
+	}
+	//rising to 103 forms 3 bricks, falling to 101 reverses with 1 brick from 102, 99 adds 2 more and 104 reverses up
+	expectedOpens := []float64{100, 101, 102, 102, 101, 100, 100, 101, 102, 103}
+	expectedCloses := []float64{101, 102, 103, 101, 100, 99, 101, 102, 103, 104}
+	if !reflect.DeepEqual(opens, expectedOpens) || !reflect.DeepEqual(closesFound, expectedCloses) {
+		t.Errorf("Unexpected bricks opens %v closes %v", opens, closesFound)
+	}
+	if bricks.Prices[0].Volume() != 2 || bricks.Prices[1].Volume() != 1 || bricks.Prices[2].Volume() != 0 {
+		t.Errorf("The volume should be assigned to the first brick of each candle")
+	}
+}
+
+func TestHeikinAshiAndSaveCSV(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/mockdata.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	heikinAshi := data.HeikinAshi()
+	first, second := data.Prices[0], data.Prices[1]
+	firstClose := (first.Open() + first.High() + first.Low() + first.Close()) / 4
+	if !isEqual(heikinAshi.Prices[0].Close(), firstClose) ||
+		!isEqual(heikinAshi.Prices[1].Open(), (heikinAshi.Prices[0].Open()+firstClose)/2) ||
+		!isEqual(heikinAshi.Prices[1].Close(), (second.Open()+second.High()+second.Low()+second.Close())/4) {
+		t.Errorf("Unexpected heikin-ashi candles %+v", heikinAshi.Prices[:2])
+	}
+
+	timestamped, _ := PricesFromCSV("../testdata/ETHUSD2.csv")
+	hourly, _ := timestamped.Resample(time.Hour)
+	for _, handler := range []*DataHandler{heikinAshi, hourly} {
+		path := filepath.Join(t.TempDir(), "bars.csv")
+		if err := handler.SaveCSV(path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := PricesFromCSV(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(loaded.Prices, handler.Prices) {
+			t.Errorf("The saved csv should load the same candles")
+		}
+	}
+}
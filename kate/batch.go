@@ -0,0 +1,161 @@
+package kate
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//Batch runs the same strategy configuration on many datasets in parallel
+type Batch struct {
+	NewStrategy func() Strategy              //creates the strategy instance used on each dataset
+	Setup       func(backtester *Backtester) //optional configuration (balance, fees...) applied before each run
+	Workers     int                          //amount of datasets processed in parallel
+}
+
+//BatchResult is the outcome of running the strategy on a single dataset
+type BatchResult struct {
+	Dataset string
+	Stats   *Statistics
+	Err     error //error loading the dataset, the stats are nil when it is set
+}
+
+//BatchResults are the results of a batch in the order of the datasets
+type BatchResults []BatchResult
+
+//NewBatch creates a batch that processes the datasets on all available CPUs
+func NewBatch(newStrategy func() Strategy) *Batch {
+	return &Batch{NewStrategy: newStrategy, Workers: runtime.NumCPU()}
+}
+
+//RunGlob runs the strategy on every csv file matching the pattern sorted by name
+func (batch *Batch) RunGlob(pattern string) (BatchResults, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match the pattern %v", pattern)
+	}
+	sort.Strings(paths)
+	return batch.Run(paths...), nil
+}
+
+//Run loads and runs the strategy on each csv file, a file that fails to load doesn't stop the other files
+func (batch *Batch) Run(paths ...string) BatchResults {
+	results := make(BatchResults, len(paths))
+	workers := batch.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index] = batch.runFile(paths[index])
+			}
+		}()
+	}
+
+	for index := range paths {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+//runFile runs the strategy on a single file, a panic of the strategy or the data is reported as the error of the file
+func (batch *Batch) runFile(path string) (result BatchResult) {
+	result = BatchResult{Dataset: path}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Stats, result.Err = nil, fmt.Errorf("the run panicked: %v", recovered)
+		}
+	}()
+
+	data, err := PricesFromCSV(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	backtester := NewBacktesterFromFactory(batch.NewStrategy, data)
+	if batch.Setup != nil {
+		batch.Setup(backtester)
+	}
+	result.Stats = backtester.Run()
+	return result
+}
+
+//Failed returns the results of the datasets that couldn't be processed
+func (results BatchResults) Failed() BatchResults {
+	var failed BatchResults
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+//Pooled combines the trades of every successful dataset into a single sequence starting from the sum of
+//the initial balances, nil is returned when no dataset was processed
+func (results BatchResults) Pooled() *Statistics {
+	var trades []Position
+	var market MarketHandler
+	initialBalance, dataPoints, processed := 0.0, 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		processed++
+		initialBalance += result.Stats.initialBalance
+		dataPoints += result.Stats.TotalDataPoints
+		trades = append(trades, result.Stats.trades...)
+		market = result.Stats.market
+	}
+	if processed == 0 {
+		return nil
+	}
+
+	stats := statisticsFromTrades(initialBalance, trades)
+	stats.TotalDataPoints = dataPoints
+	stats.market = market
+	return stats
+}
+
+//String renders a table with the metrics of each dataset followed by the pooled metrics and the failures
+func (results BatchResults) String() string {
+	rows := [][]string{append([]string{"Dataset"}, MetricNames()...)}
+
+	addRow := func(name string, stats *Statistics) {
+		row := []string{name}
+		for _, metric := range stats.metrics() {
+			row = append(row, metric.String())
+		}
+		rows = append(rows, row)
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			addRow(filepath.Base(result.Dataset), result.Stats)
+		}
+	}
+	if pooled := results.Pooled(); pooled != nil {
+		addRow("Pooled", pooled)
+	}
+
+	var text strings.Builder
+	text.WriteString(renderTable(rows, nil))
+	for _, failed := range results.Failed() {
+		fmt.Fprintf(&text, "\nFailed %v: %v", failed.Dataset, failed.Err)
+	}
+	return text.String()
+}
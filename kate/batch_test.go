@@ -0,0 +1,102 @@
+package kate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.csv"), []byte("open,high,low,close,volume\n1,2,x,1,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := NewBatch(func() Strategy { return newSimpleStrategy() })
+	batch.Setup = func(backtester *Backtester) {
+		backtester.SetBalance(1000)
+		backtester.SetFixedTradeAmount(20)
+	}
+	results := batch.Run("../testdata/ETHUSD2.csv", filepath.Join(dir, "broken.csv"), "../testdata/ETHUSD3.csv",
+		filepath.Join(dir, "missing.csv"))
+
+	if len(results) != 4 || len(results.Failed()) != 2 {
+		t.Fatalf("Expected 2 of the 4 datasets to fail, found %v failures", len(results.Failed()))
+	}
+	if results[0].Stats.TotalTrades != 21 || results[2].Stats.TotalTrades != 71 {
+		t.Errorf("Unexpected trades per dataset %v and %v", results[0].Stats.TotalTrades, results[2].Stats.TotalTrades)
+	}
+
+	pooled := results.Pooled()
+	netProfit := results[0].Stats.NetProfit + results[2].Stats.NetProfit
+	if pooled.TotalTrades != 92 || pooled.initialBalance != 2000 || !isEqual(pooled.NetProfit, netProfit) ||
+		pooled.TotalDataPoints != 1264+2946 {
+		t.Errorf("Unexpected pooled statistics %+v", pooled)
+	}
+
+	table := results.String()
+	for _, expected := range []string{"ETHUSD2.csv", "ETHUSD3.csv", "Pooled", "Failed", "missing.csv"} {
+		if !strings.Contains(table, expected) {
+			t.Errorf("The batch table is missing %v:\n%v", expected, table)
+		}
+	}
+
+	if _, err := batch.RunGlob(filepath.Join(dir, "*.json")); err == nil {
+		t.Errorf("A pattern without matches should return a error")
+	}
+	globbed, err := batch.RunGlob("../testdata/ETHUSD[23].csv")
+	if err != nil || len(globbed) != 2 || globbed[0].Dataset != "../testdata/ETHUSD2.csv" {
+		t.Errorf("Expected the glob to match ETHUSD2 and ETHUSD3 in order, found %v (%v)", globbed, err)
+	}
+}
+
+func TestBatchMalformedFiles(t *testing.T) {
+	dir := t.TempDir()
+	prices, err := os.ReadFile("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a_prices.csv":   string(prices),
+		"b_short.csv":    "open,high,low,close\n1,2,1,1\n",
+		"c_empty.csv":    "",
+		"d_prices.csv":   string(prices),
+		"e_panicked.csv": "open,high,low,close,volume\n1,2,1,1,1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch := NewBatch(func() Strategy { return newSimpleStrategy() })
+	batch.Setup = func(backtester *Backtester) {
+		//a strategy failing only on one of the datasets
+		if len(backtester.dataHandler.Prices) == 1 {
+			panic("unexpected data")
+		}
+	}
+	results, err := batch.RunGlob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		failed bool
+		err    string
+	}{
+		{false, ""}, {true, "header"}, {true, ""}, {false, ""}, {true, "unexpected data"},
+	}
+	for i, test := range tests {
+		result := results[i]
+		if (result.Err != nil) != test.failed || (result.Err == nil) != (result.Stats != nil) {
+			t.Errorf("%v: unexpected result %+v", result.Dataset, result)
+		} else if test.err != "" && !strings.Contains(result.Err.Error(), test.err) {
+			t.Errorf("%v: expected the error '%v', found %v", result.Dataset, test.err, result.Err)
+		}
+	}
+	if results[0].Stats == nil || results[3].Stats == nil || results[0].Stats.TotalTrades != results[3].Stats.TotalTrades {
+		t.Errorf("The valid files should be processed despite the malformed ones")
+	}
+}
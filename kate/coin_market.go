@@ -46,3 +46,13 @@ func (marketHandler *CoinMarket) limitFee(position *Position) float64 {
 func (marketHandler *CoinMarket) liquidationFee(position *Position) float64 {
 	return 2 * marketHandler.marketFee(position)
 }
+
+//notional is the value in COIN of a position size (in USD contracts) at the provided price
+func (marketHandler *CoinMarket) notional(size, price float64) float64 {
+	return size / price
+}
+
+//funding calculates the funding payment in COIN for the position value at the provided price
+func (marketHandler *CoinMarket) funding(position *Position, price, rate float64) float64 {
+	return position.Size / price * rate
+}
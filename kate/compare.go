@@ -0,0 +1,183 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//String renders the metrics of the run as an aligned table
+func (stats *Statistics) String() string {
+	metrics := stats.metrics()
+	rows := make([][]string, 0, len(metrics)+1)
+	rows = append(rows, []string{"Metric", "Value"})
+	for _, metric := range metrics {
+		rows = append(rows, []string{metric.name, metric.String()})
+	}
+	return renderTable(rows, nil)
+}
+
+//MetricNames lists the names of the metrics that can be used to compare and rank runs
+func MetricNames() []string {
+	var names []string
+	for _, metric := range (&Statistics{}).metrics() {
+		names = append(names, metric.name)
+	}
+	return names
+}
+
+//Metric returns the value of a metric by its name (case insensitive) as presented in the tables,
+//percentages such as the win rate and drawdown are returned in the 0-100 range
+func (stats *Statistics) Metric(name string) (float64, error) {
+	m, err := stats.findMetric(name)
+	return m.value, err
+}
+
+func (stats *Statistics) findMetric(name string) (metric, error) {
+	for _, m := range stats.metrics() {
+		if strings.EqualFold(m.name, strings.TrimSpace(name)) {
+			return m, nil
+		}
+	}
+	return metric{}, fmt.Errorf("unknown metric '%v', the available metrics are: %v", name,
+		strings.Join(MetricNames(), ", "))
+}
+
+//Comparison presents the metrics of several runs side by side
+type Comparison struct {
+	Names []string
+	Runs  []*Statistics
+}
+
+//Compare creates a comparison of the runs, the runs are named by their position unless Names is changed
+func Compare(runs ...*Statistics) *Comparison {
+	names := make([]string, len(runs))
+	for i := range runs {
+		names[i] = "Run " + strconv.Itoa(i+1)
+	}
+	return &Comparison{Names: names, Runs: runs}
+}
+
+//SortBy orders the runs from the best to the worst value of the metric
+func (comparison *Comparison) SortBy(metricName string) error {
+	if len(comparison.Names) != len(comparison.Runs) {
+		return fmt.Errorf("the comparison has %v names for %v runs", len(comparison.Names), len(comparison.Runs))
+	}
+	values := make([]metric, len(comparison.Runs))
+	for i, run := range comparison.Runs {
+		m, err := run.findMetric(metricName)
+		if err != nil {
+			return err
+		}
+		values[i] = m
+	}
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return isBetter(values[order[i]], values[order[j]])
+	})
+
+	names := make([]string, len(order))
+	runs := make([]*Statistics, len(order))
+	for position, index := range order {
+		names[position], runs[position] = comparison.Names[index], comparison.Runs[index]
+	}
+	comparison.Names, comparison.Runs = names, runs
+	return nil
+}
+
+//String renders a table with a column for each run. Rows where the runs differ are marked with '>'
+//and the best value of each row is marked with '*'
+func (comparison *Comparison) String() string {
+	if len(comparison.Runs) == 0 {
+		return "no runs to compare\n"
+	}
+
+	header := []string{"Metric"}
+	for i := range comparison.Runs {
+		name := "Run " + strconv.Itoa(i+1)
+		if i < len(comparison.Names) {
+			name = comparison.Names[i]
+		}
+		header = append(header, name+"  ")
+	}
+
+	runMetrics := make([][]metric, len(comparison.Runs))
+	for i, run := range comparison.Runs {
+		runMetrics[i] = run.metrics()
+	}
+
+	rows := [][]string{header}
+	var marked []bool
+	for row := range runMetrics[0] {
+		cells := []string{runMetrics[0][row].name}
+		best, differ := 0, false
+		for i := range runMetrics {
+			if runMetrics[i][row].String() != runMetrics[0][row].String() {
+				differ = true
+			}
+			if isBetter(runMetrics[i][row], runMetrics[best][row]) {
+				best = i
+			}
+		}
+		for i := range runMetrics {
+			cell := runMetrics[i][row].String()
+			if differ && i == best && runMetrics[i][row].better != neutral {
+				cell += " *"
+			} else {
+				cell += "  "
+			}
+			cells = append(cells, cell)
+		}
+		rows = append(rows, cells)
+		marked = append(marked, differ)
+	}
+	return renderTable(rows, append([]bool{false}, marked...))
+}
+
+//isBetter checks if the value of the first metric is better than the second, NaN values are always the worst
+func isBetter(first, second metric) bool {
+	if math.IsNaN(second.value) {
+		return !math.IsNaN(first.value)
+	}
+	if first.better == lowerIsBetter {
+		return first.value < second.value
+	}
+	return first.value > second.value
+}
+
+//renderTable aligns the first column to the left and the other columns to the right,
+//the rows flagged as marked are prefixed with '>'
+func renderTable(rows [][]string, marked []bool) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for column, cell := range row {
+			if len(cell) > widths[column] {
+				widths[column] = len(cell)
+			}
+		}
+	}
+
+	var table strings.Builder
+	for i, row := range rows {
+		if i < len(marked) && marked[i] {
+			table.WriteString("> ")
+		} else {
+			table.WriteString("  ")
+		}
+		for column, cell := range row {
+			if column == 0 {
+				fmt.Fprintf(&table, "%-*s", widths[column], cell)
+			} else {
+				fmt.Fprintf(&table, "  %*s", widths[column], cell)
+			}
+		}
+		table.WriteString("\n")
+	}
+	return table.String()
+}
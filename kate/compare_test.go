@@ -0,0 +1,69 @@
+package kate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatisticsString(t *testing.T) {
+	stats := &Statistics{initialBalance: 1000, NetProfit: 12.5, ROIPercentage: 1.25, WinRate: 0.5, TotalTrades: 8}
+	table := stats.String()
+
+	lines := strings.Split(strings.TrimRight(table, "\n"), "\n")
+	if len(lines) != len(MetricNames())+1 {
+		t.Fatalf("The table should contain a line for each metric plus the header:\n%v", table)
+	}
+	for _, line := range lines {
+		if len(line) != len(lines[0]) {
+			t.Errorf("The lines of the table are not aligned:\n%v", table)
+			break
+		}
+	}
+
+	for _, expected := range []string{"Net profit", "12.50", "Win rate", "50.00%", "1012.50"} {
+		if !strings.Contains(table, expected) {
+			t.Errorf("The table is missing the value '%v':\n%v", expected, table)
+		}
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	first := &Statistics{initialBalance: 100, NetProfit: -5, SharpeRatio: 0.4, MaxDrawdown: 0.1, TotalTrades: 3}
+	second := &Statistics{initialBalance: 100, NetProfit: 7, SharpeRatio: 0.2, MaxDrawdown: 0.3, TotalTrades: 3}
+	third := &Statistics{initialBalance: 100, NetProfit: 2, SharpeRatio: 0.9, MaxDrawdown: 0.05, TotalTrades: 3}
+
+	var tests = []struct {
+		metric        string
+		expectedOrder []string
+	}{
+		{"Net profit", []string{"second", "third", "first"}},
+		{"sharpe ratio", []string{"third", "first", "second"}},
+		{"Max drawdown", []string{"third", "first", "second"}},
+	}
+
+	for _, test := range tests {
+		comparison := Compare(first, second, third)
+		comparison.Names = []string{"first", "second", "third"}
+		if err := comparison.SortBy(test.metric); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(comparison.Names, ",") != strings.Join(test.expectedOrder, ",") {
+			t.Errorf("Sorting by %v resulted in %v the expected order is %v", test.metric, comparison.Names, test.expectedOrder)
+		}
+	}
+
+	comparison := Compare(first, second, third)
+	table := comparison.String()
+	for _, line := range strings.Split(table, "\n") {
+		if strings.Contains(line, "Net profit") && (!strings.HasPrefix(line, ">") || !strings.Contains(line, "7.00 *")) {
+			t.Errorf("The best net profit should be highlighted:\n%v", table)
+		}
+		if strings.Contains(line, "Total trades") && (strings.HasPrefix(line, ">") || strings.Contains(line, "*")) {
+			t.Errorf("Equal values should not be highlighted:\n%v", table)
+		}
+	}
+
+	if err := comparison.SortBy("unknown"); err == nil {
+		t.Errorf("A error was expected when sorting by a unknown metric")
+	}
+}
@@ -0,0 +1,326 @@
+package kate
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+//composite are the strategies combined by a composite strategy, every candle and optional hook received by the
+//composite is forwarded to all of them so each strategy keeps its indicators updated even when it is not consulted.
+//A strategy passed more than once receives each candle and hook only once.
+type composite []Strategy
+
+//FilterStrategy runs a strategy only when a regime strategy allows it: a position requested by the strategy is
+//opened only when the regime would open a position in the same direction on the same candle. The regime positions
+//are never opened, for example a regime allowing long positions above sma(200) and short positions below it.
+type FilterStrategy struct {
+	composite
+	ExitOnChange bool //closes the open position when the regime no longer allows its direction
+
+	regime, strategy Strategy
+	latest           DataPoint
+	checked          bool
+	allowed          *OpenPositionEvt //position the regime would open on the latest candle
+}
+
+//EnsembleStrategy opens a position when the weighted votes of the strategies in a direction are more than the
+//threshold of the total weight, the strategies that don't request a position or request a invalid direction vote
+//for neither direction.
+//The position requested by the strategy with the highest weight among the winning votes is opened and managed by it.
+type EnsembleStrategy struct {
+	composite
+	Threshold float64 //fraction of the total weight needed to open a position, 0.5 is a strict majority
+
+	weights []float64
+	leader  Strategy //strategy managing the open position
+}
+
+//EntryExitStrategy opens the positions of a entry strategy and manages them with a exit strategy, which sets the
+//stoploss and takeprofit and closes positions when it implements ExitStrategy
+type EntryExitStrategy struct {
+	composite
+	entry, exit Strategy
+}
+
+//NewFilterStrategy creates a strategy that opens the positions of the strategy allowed by the regime
+func NewFilterStrategy(regime, strategy Strategy) *FilterStrategy {
+	return &FilterStrategy{composite: composite{regime, strategy}, regime: regime, strategy: strategy}
+}
+
+//NewMajorityVote creates a ensemble where every strategy has the same weight
+func NewMajorityVote(strategies ...Strategy) *EnsembleStrategy {
+	weights := make([]float64, len(strategies))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return &EnsembleStrategy{composite: strategies, Threshold: 0.5, weights: weights}
+}
+
+//NewWeightedEnsemble creates a ensemble where the vote of each strategy has the weight on the same position
+func NewWeightedEnsemble(weights []float64, strategies ...Strategy) (*EnsembleStrategy, error) {
+	if len(weights) != len(strategies) {
+		return nil, fmt.Errorf("expected %v weights, found %v", len(strategies), len(weights))
+	}
+	for i, weight := range weights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("the weight of the strategy %v must be positive, found %v", i, weight)
+		}
+	}
+	return &EnsembleStrategy{composite: strategies, Threshold: 0.5, weights: append([]float64{}, weights...)}, nil
+}
+
+//NewEntryExitStrategy creates a strategy with the entries of a strategy and the exits of another one
+func NewEntryExitStrategy(entry, exit Strategy) *EntryExitStrategy {
+	return &EntryExitStrategy{composite: composite{entry, exit}, entry: entry, exit: exit}
+}
+
+//PreProcessIndicators updates the regime and the strategy
+func (strategy *FilterStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	strategy.composite.PreProcessIndicators(latestPrice)
+	strategy.latest, strategy.checked, strategy.allowed = latestPrice, false, nil
+}
+
+//OpenNewPosition opens the position of the strategy when the regime allows its direction
+func (strategy *FilterStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	allowed := strategy.regimeAllows()
+	event := strategy.strategy.OpenNewPosition(latestPrice)
+	if event == nil || allowed == nil || allowed.Direction != event.Direction {
+		return nil
+	}
+	return event
+}
+
+//ClosePosition closes the position when requested by the strategy or, with ExitOnChange, when the regime no
+//longer allows its direction
+func (strategy *FilterStrategy) ClosePosition(openPosition Position) bool {
+	if exit, ok := listenerOf(strategy.strategy).(ExitStrategy); ok && exit.ClosePosition(openPosition) {
+		return true
+	}
+	if !strategy.ExitOnChange {
+		return false
+	}
+	allowed := strategy.regimeAllows()
+	return allowed == nil || allowed.Direction != openPosition.Direction
+}
+
+//SetStoploss the stoploss is set by the strategy
+func (strategy *FilterStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	return strategy.strategy.SetStoploss(openPosition)
+}
+
+//SetTakeProfit the takeprofit is set by the strategy
+func (strategy *FilterStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return strategy.strategy.SetTakeProfit(openPosition)
+}
+
+//regimeAllows asks the regime for the position it would open on the latest candle, only once per candle
+func (strategy *FilterStrategy) regimeAllows() *OpenPositionEvt {
+	if !strategy.checked {
+		strategy.allowed, strategy.checked = strategy.regime.OpenNewPosition(strategy.latest), true
+	}
+	return strategy.allowed
+}
+
+//OpenNewPosition counts the votes of the strategies and opens the position of the winning direction
+func (strategy *EnsembleStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	var total float64
+	var votes [2]float64
+	var leaders [2]int
+	events := make([]*OpenPositionEvt, len(strategy.composite))
+	for i, member := range strategy.composite {
+		total += strategy.weights[i]
+		if events[i] = member.OpenNewPosition(latestPrice); events[i] == nil {
+			continue
+		}
+		direction := events[i].Direction
+		if direction != LONG && direction != SHORT {
+			continue
+		}
+		if votes[direction] == 0 || strategy.weights[i] > strategy.weights[leaders[direction]] {
+			leaders[direction] = i
+		}
+		votes[direction] += strategy.weights[i]
+	}
+
+	direction := LONG
+	if votes[SHORT] > votes[LONG] {
+		direction = SHORT
+	}
+	if votes[direction] == 0 || votes[direction] <= strategy.Threshold*total {
+		return nil
+	}
+	strategy.leader = strategy.composite[leaders[direction]]
+	return events[leaders[direction]]
+}
+
+//ClosePosition closes the position when requested by the strategy managing it
+func (strategy *EnsembleStrategy) ClosePosition(openPosition Position) bool {
+	exit, ok := listenerOf(strategy.leader).(ExitStrategy)
+	return ok && exit.ClosePosition(openPosition)
+}
+
+//SetStoploss the stoploss is set by the strategy managing the position
+func (strategy *EnsembleStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	if strategy.leader == nil {
+		return nil
+	}
+	return strategy.leader.SetStoploss(openPosition)
+}
+
+//SetTakeProfit the takeprofit is set by the strategy managing the position
+func (strategy *EnsembleStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	if strategy.leader == nil {
+		return nil
+	}
+	return strategy.leader.SetTakeProfit(openPosition)
+}
+
+//OnStart forgets the strategy managing the position of the previous run
+func (strategy *EnsembleStrategy) OnStart(config RunConfig) {
+	strategy.leader = nil
+	strategy.composite.OnStart(config)
+}
+
+//OpenNewPosition opens the position of the entry strategy
+func (strategy *EntryExitStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	return strategy.entry.OpenNewPosition(latestPrice)
+}
+
+//ClosePosition closes the position when requested by the exit strategy
+func (strategy *EntryExitStrategy) ClosePosition(openPosition Position) bool {
+	exit, ok := listenerOf(strategy.exit).(ExitStrategy)
+	return ok && exit.ClosePosition(openPosition)
+}
+
+//SetStoploss the stoploss is set by the exit strategy
+func (strategy *EntryExitStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	return strategy.exit.SetStoploss(openPosition)
+}
+
+//SetTakeProfit the takeprofit is set by the exit strategy
+func (strategy *EntryExitStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return strategy.exit.SetTakeProfit(openPosition)
+}
+
+//repeated checks if the strategy on the index is also on a previous index, the StrategyV2 adapted more than once
+//is the same strategy
+func (strategies composite) repeated(index int) bool {
+	member := listenerOf(strategies[index])
+	if !reflect.TypeOf(member).Comparable() {
+		return false
+	}
+	for _, previous := range strategies[:index] {
+		if listenerOf(previous) == member {
+			return true
+		}
+	}
+	return false
+}
+
+func (strategies composite) PreProcessIndicators(latestPrice DataPoint) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		strategy.PreProcessIndicators(latestPrice)
+	}
+}
+
+func (strategies composite) PreProcessTimeframe(timeframe string, closedCandle DataPoint) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(TimeframeStrategy); ok {
+			listener.PreProcessTimeframe(timeframe, closedCandle)
+		}
+	}
+}
+
+func (strategies composite) SetHistory(history *Series) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(HistoryStrategy); ok {
+			listener.SetHistory(history)
+		}
+	}
+}
+
+//setContext is forwarded to every adapter of a StrategyV2, including the ones repeated, as each adapter keeps its own
+//context
+func (strategies composite) setContext(ctx *Context) {
+	for _, strategy := range strategies {
+		if listener, ok := strategy.(contextStrategy); ok {
+			listener.setContext(ctx)
+		}
+	}
+}
+
+func (strategies composite) OnStart(config RunConfig) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(StartListener); ok {
+			listener.OnStart(config)
+		}
+	}
+}
+
+func (strategies composite) OnEnd(stats *Statistics) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(EndListener); ok {
+			listener.OnEnd(stats)
+		}
+	}
+}
+
+func (strategies composite) OnOrderFilled(order Order) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(OrderFilledListener); ok {
+			listener.OnOrderFilled(order)
+		}
+	}
+}
+
+func (strategies composite) OnPositionClosed(position Position, reason ExitReason) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(PositionClosedListener); ok {
+			listener.OnPositionClosed(position, reason)
+		}
+	}
+}
+
+func (strategies composite) OnLiquidation(position Position) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(LiquidationListener); ok {
+			listener.OnLiquidation(position)
+		}
+	}
+}
+
+func (strategies composite) OnDayChange(day time.Time) {
+	for i, strategy := range strategies {
+		if strategies.repeated(i) {
+			continue
+		}
+		if listener, ok := listenerOf(strategy).(DayChangeListener); ok {
+			listener.OnDayChange(day)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package kate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+//scriptedStrategy decides its positions by the index of the candle, the hooks are counted
+type scriptedStrategy struct {
+	candle, started, closed int
+	open                    func(candle int) *OpenPositionEvt
+	exit                    func(candle int, openPosition Position) bool
+	stoploss                func(openPosition Position) *StoplossEvt
+}
+
+func (strategy *scriptedStrategy) OnStart(config RunConfig) {
+	strategy.candle = -1
+	strategy.started++
+}
+
+func (strategy *scriptedStrategy) OnPositionClosed(position Position, reason ExitReason) {
+	strategy.closed++
+}
+
+func (strategy *scriptedStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	strategy.candle++
+}
+
+func (strategy *scriptedStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	if strategy.open == nil {
+		return nil
+	}
+	return strategy.open(strategy.candle)
+}
+
+func (strategy *scriptedStrategy) ClosePosition(openPosition Position) bool {
+	return strategy.exit != nil && strategy.exit(strategy.candle, openPosition)
+}
+
+func (strategy *scriptedStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	if strategy.stoploss == nil {
+		return nil
+	}
+	return strategy.stoploss(openPosition)
+}
+
+func (strategy *scriptedStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+//holdFor closes the positions after the amount of candles
+func holdFor(candles int) func(candle int, openPosition Position) bool {
+	return func(candle int, openPosition Position) bool {
+		return candle-openPosition.EntryCandle >= candles
+	}
+}
+
+func TestFilterStrategy(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	//the regime allows long positions until the candle 100 and short positions until the candle 200
+	regime := func(candle int) *OpenPositionEvt {
+		switch {
+		case candle < 100:
+			return &OpenPositionEvt{Direction: LONG}
+		case candle < 200:
+			return &OpenPositionEvt{Direction: SHORT}
+		}
+		return nil
+	}
+	alternating := func(candle int) *OpenPositionEvt {
+		if candle%10 != 5 {
+			return nil
+		}
+		if candle%20 == 5 {
+			return &OpenPositionEvt{Direction: LONG, Leverage: 2}
+		}
+		return &OpenPositionEvt{Direction: SHORT, Leverage: 2}
+	}
+	late := func(candle int) *OpenPositionEvt {
+		if candle == 96 {
+			return &OpenPositionEvt{Direction: LONG, Leverage: 2}
+		}
+		return nil
+	}
+
+	var tests = []struct {
+		name         string
+		open         func(candle int) *OpenPositionEvt
+		exitOnChange bool
+		entries      []int
+		closes       []int
+	}{
+		{"alternating entries", alternating, false, []int{5, 25, 45, 65, 85, 115, 135, 155, 175, 195},
+			[]int{10, 30, 50, 70, 90, 120, 140, 160, 180, 200}},
+		{"held after the regime change", late, false, []int{96}, []int{101}},
+		{"closed on the regime change", late, true, []int{96}, []int{100}},
+	}
+
+	for _, test := range tests {
+		inner := &scriptedStrategy{open: test.open, exit: holdFor(5)}
+		strategy := NewFilterStrategy(&scriptedStrategy{open: regime}, inner)
+		strategy.ExitOnChange = test.exitOnChange
+		trades := NewBacktester(strategy, data).Run().Trades()
+		if len(trades) != len(test.entries) {
+			t.Fatalf("%v: expected %v trades, found %v", test.name, len(test.entries), len(trades))
+		}
+		for i, trade := range trades {
+			if trade.EntryCandle != test.entries[i] || trade.CloseCandle != test.closes[i] || trade.Leverage != 2 {
+				t.Errorf("%v: expected the trade from %v to %v, found %+v", test.name, test.entries[i], test.closes[i],
+					trade)
+			}
+			if (trade.EntryCandle < 100) != (trade.Direction == LONG) {
+				t.Errorf("%v: the %v trade on candle %v is not allowed by the regime", test.name, trade.Direction,
+					trade.EntryCandle)
+			}
+		}
+		if inner.started != 1 || inner.closed != len(trades) {
+			t.Errorf("%v: the hooks should be forwarded, found %v starts and %v closed positions", test.name,
+				inner.started, inner.closed)
+		}
+	}
+}
+
+func TestEnsembleStrategy(t *testing.T) {
+	var step int
+	members := make([]Strategy, 3)
+	var votes [][3]string
+	for i := range members {
+		member := i
+		members[i] = &scriptedStrategy{
+			open: func(candle int) *OpenPositionEvt {
+				switch votes[step][member] {
+				case "long":
+					return &OpenPositionEvt{Direction: LONG, Leverage: uint(member + 1)}
+				case "short":
+					return &OpenPositionEvt{Direction: SHORT, Leverage: uint(member + 1)}
+				case "invalid":
+					return &OpenPositionEvt{Direction: Direction(5), Leverage: uint(member + 1)}
+				}
+				return nil
+			},
+			stoploss: func(openPosition Position) *StoplossEvt {
+				return &StoplossEvt{Price: float64(member + 1)}
+			},
+		}
+	}
+	majority := NewMajorityVote(members...)
+	weighted, err := NewWeightedEnsemble([]float64{1, 1, 3}, members...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name      string
+		ensemble  *EnsembleStrategy
+		votes     [3]string
+		open      bool
+		direction Direction
+		leader    uint
+	}{
+		{"majority long", majority, [3]string{"long", "long", ""}, true, LONG, 1},
+		{"majority split", majority, [3]string{"long", "short", ""}, false, LONG, 0},
+		{"majority short", majority, [3]string{"long", "short", "short"}, true, SHORT, 2},
+		{"majority single vote", majority, [3]string{"", "", "long"}, false, LONG, 0},
+		{"weighted outvoted", weighted, [3]string{"long", "long", "short"}, true, SHORT, 3},
+		{"weighted minority", weighted, [3]string{"long", "long", ""}, false, LONG, 0},
+		{"weighted single vote", weighted, [3]string{"", "", "long"}, true, LONG, 3},
+		{"majority invalid direction", majority, [3]string{"long", "invalid", ""}, false, LONG, 0},
+		{"weighted invalid direction", weighted, [3]string{"short", "", "invalid"}, false, LONG, 0},
+		{"weighted invalid leader", weighted, [3]string{"long", "long", "invalid"}, false, LONG, 0},
+	}
+
+	candle := NewDataPoint(100, 100, 100, 100, 1, time.Time{})
+	for _, test := range tests {
+		step = len(votes)
+		votes = append(votes, test.votes)
+		test.ensemble.PreProcessIndicators(candle)
+		event := test.ensemble.OpenNewPosition(candle)
+		if (event != nil) != test.open {
+			t.Fatalf("%v: unexpected position %+v", test.name, event)
+		}
+		if event == nil {
+			continue
+		}
+		if event.Direction != test.direction || event.Leverage != test.leader {
+			t.Errorf("%v: expected the %v position of the strategy %v, found %+v", test.name, test.direction,
+				test.leader, event)
+		}
+		if stoploss := test.ensemble.SetStoploss(Position{}); stoploss == nil || stoploss.Price != float64(test.leader) {
+			t.Errorf("%v: the position should be managed by the strategy %v, found %+v", test.name, test.leader,
+				stoploss)
+		}
+	}
+
+	if _, err := NewWeightedEnsemble([]float64{1, 2}, members...); err == nil {
+		t.Errorf("expected a error for missing weights")
+	}
+	if _, err := NewWeightedEnsemble([]float64{1, 0, 2}, members...); err == nil {
+		t.Errorf("expected a error for a weight that is not positive")
+	}
+}
+
+func TestEntryExitStrategy(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	entry := &scriptedStrategy{
+		open: func(candle int) *OpenPositionEvt {
+			if candle%10 == 0 {
+				return &OpenPositionEvt{Direction: LONG, Leverage: 3}
+			}
+			return nil
+		},
+		exit: func(candle int, openPosition Position) bool {
+			t.Error("the entry strategy should not close positions")
+			return false
+		},
+		stoploss: func(openPosition Position) *StoplossEvt {
+			t.Error("the entry strategy should not set the stoploss")
+			return nil
+		},
+	}
+	exit := &scriptedStrategy{
+		open: func(candle int) *OpenPositionEvt {
+			t.Error("the exit strategy should not open positions")
+			return nil
+		},
+		exit: holdFor(3),
+		stoploss: func(openPosition Position) *StoplossEvt {
+			if openPosition.Stoploss == 0 {
+				return &StoplossEvt{Price: openPosition.EntryPrice / 2}
+			}
+			return nil
+		},
+	}
+
+	stats := NewBacktester(NewEntryExitStrategy(entry, exit), data).Run()
+	if stats.TotalTrades == 0 {
+		t.Fatal("expected trades opened by the entry strategy")
+	}
+	for _, trade := range stats.Trades() {
+		if trade.EntryCandle%10 != 0 || trade.CloseCandle != trade.EntryCandle+3 || trade.ExitReason != MarketExit {
+			t.Errorf("expected the positions to be opened by the entry and closed by the exit, found %+v", trade)
+		}
+		if !isEqual(trade.Stoploss, trade.EntryPrice/2) {
+			t.Errorf("expected the stoploss set by the exit strategy %v, found %v", trade.EntryPrice/2, trade.Stoploss)
+		}
+	}
+	if entry.closed != stats.TotalTrades || exit.closed != stats.TotalTrades {
+		t.Errorf("the closed positions should be forwarded to both strategies, found %v and %v", entry.closed,
+			exit.closed)
+	}
+}
+
+func TestCompositeRepeatedMembers(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	hourly, _ := data.Resample(time.Hour)
+
+	member := &scriptedStrategy{
+		open: func(candle int) *OpenPositionEvt {
+			if candle%10 == 0 {
+				return &OpenPositionEvt{Direction: LONG, Leverage: 3}
+			}
+			return nil
+		},
+		exit: holdFor(3),
+	}
+	stats := NewBacktester(NewEntryExitStrategy(member, member), data).Run()
+	if member.started != 1 || member.closed != stats.TotalTrades {
+		t.Errorf("the hooks should be forwarded once, found %v starts and %v closed positions for %v trades",
+			member.started, member.closed, stats.TotalTrades)
+	}
+	//the last candle of the data is never processed
+	if member.candle != len(data.Prices)-2 {
+		t.Errorf("the candles should be forwarded once, found %v candles instead of %v", member.candle+1,
+			len(data.Prices)-1)
+	}
+
+	//the StrategyV2 members receive the history, timeframes and exits of the composite
+	single, repeated := &optionalV2Strategy{}, &optionalV2Strategy{}
+	var results [2]*Statistics
+	for i, strategy := range []Strategy{WithContext(single),
+		NewEntryExitStrategy(WithContext(repeated), WithContext(repeated))} {
+		backtester := NewBacktester(strategy, data)
+		if err := backtester.AddTimeframe("1h", hourly); err != nil {
+			t.Fatal(err)
+		}
+		results[i] = backtester.Run()
+	}
+	if results[1].TotalTrades == 0 || repeated.timeframes != single.timeframes ||
+		!reflect.DeepEqual(results[0].Trades(), results[1].Trades()) {
+		t.Errorf("expected the %v trades and %v hourly candles of the StrategyV2, found %v trades and %v candles",
+			results[0].TotalTrades, single.timeframes, results[1].TotalTrades, repeated.timeframes)
+	}
+}
@@ -0,0 +1,146 @@
+package kate
+
+import "time"
+
+//Context gives strategies read-only access to the account and market state of the run
+type Context struct {
+	sim *simulation
+}
+
+//StrategyV2 is a Strategy that receives the Context of the run on every callback,
+//it is used by the backtester after being adapted with WithContext
+type StrategyV2 interface {
+	PreProcessIndicators(ctx *Context, latestPrice DataPoint)
+	OpenNewPosition(ctx *Context, latestPrice DataPoint) *OpenPositionEvt
+	SetStoploss(ctx *Context, openPosition Position) *StoplossEvt
+	SetTakeProfit(ctx *Context, openPosition Position) *TakeProfitEvt
+}
+
+//contextStrategy is implemented by strategies that receive the Context at the start of every run
+type contextStrategy interface {
+	setContext(ctx *Context)
+}
+
+//strategyV2Adapter calls a StrategyV2 with the Context of the run
+type strategyV2Adapter struct {
+	strategy StrategyV2
+	ctx      *Context
+}
+
+//WithContext adapts a StrategyV2 to be used anywhere a Strategy is accepted
+func WithContext(strategy StrategyV2) Strategy {
+	return &strategyV2Adapter{strategy: strategy}
+}
+
+//Balance is the balance of the account without the open position
+func (ctx *Context) Balance() float64 {
+	return ctx.sim.exchangeHandler.balance
+}
+
+//Equity is the balance including the unrealized pnl, fees and funding of the open position
+func (ctx *Context) Equity() float64 {
+	return ctx.sim.exchangeHandler.equity()
+}
+
+//OpenPosition returns a copy of the open position, false is returned when there is no open position
+func (ctx *Context) OpenPosition() (Position, bool) {
+	if ctx.sim.exchangeHandler.openPosition == nil {
+		return Position{}, false
+	}
+	return *ctx.sim.exchangeHandler.openPosition, true
+}
+
+//Trades returns the positions closed until now
+func (ctx *Context) Trades() []Position {
+	trades := make([]Position, len(ctx.sim.exchangeHandler.tradeHistory))
+	for i, position := range ctx.sim.exchangeHandler.tradeHistory {
+		trades[i] = *position
+	}
+	return trades
+}
+
+//Orders returns every order submitted until now including the rejected ones and the executed stoploss/takeprofit
+func (ctx *Context) Orders() []Order {
+	orders := make([]Order, len(ctx.sim.exchangeHandler.orderHistory))
+	for i, order := range ctx.sim.exchangeHandler.orderHistory {
+		orders[i] = *order
+	}
+	return orders
+}
+
+//PendingOrders returns the stoploss and takeprofit orders waiting to be triggered on the open position
+func (ctx *Context) PendingOrders() []Order {
+	position := ctx.sim.exchangeHandler.openPosition
+	if position == nil {
+		return nil
+	}
+
+	var pending []Order
+	for _, trigger := range []struct {
+		action    OrderAction
+		orderType OrderType
+		price     float64
+	}{
+		{StoplossAction, MARKET, position.Stoploss},
+		{TakeProfitAction, LIMIT, position.TakeProfit},
+	} {
+		if trigger.price > 0 {
+			pending = append(pending, Order{Candle: position.EntryCandle, Time: position.EntryTime, Action: trigger.action,
+				Type: trigger.orderType, Direction: position.Direction, Leverage: position.Leverage, Price: trigger.price,
+				Status: Placed})
+		}
+	}
+	return pending
+}
+
+//Candle is the index of the latest candle in the price data
+func (ctx *Context) Candle() int {
+	return ctx.sim.exchangeHandler.currentCandle
+}
+
+//Time is the close time of the latest candle, a zero value denotes that the data has no timestamps
+func (ctx *Context) Time() time.Time {
+	return ctx.sim.exchangeHandler.currentTime
+}
+
+//Price is the close price of the latest candle
+func (ctx *Context) Price() float64 {
+	return ctx.sim.exchangeHandler.currentPrice
+}
+
+//History is the series with the latest candles
+func (ctx *Context) History() *Series {
+	return ctx.sim.history
+}
+
+//forRun gives every run its own adapter of a StrategyV2, so concurrent runs of the same strategy keep their contexts
+func forRun(strategy Strategy) Strategy {
+	if adapter, ok := strategy.(*strategyV2Adapter); ok {
+		return &strategyV2Adapter{strategy: adapter.strategy}
+	}
+	return strategy
+}
+
+func (adapter *strategyV2Adapter) setContext(ctx *Context) {
+	adapter.ctx = ctx
+}
+
+//PreProcessIndicators calls the StrategyV2 with the Context
+func (adapter *strategyV2Adapter) PreProcessIndicators(latestPrice DataPoint) {
+	adapter.strategy.PreProcessIndicators(adapter.ctx, latestPrice)
+}
+
+//OpenNewPosition calls the StrategyV2 with the Context
+func (adapter *strategyV2Adapter) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	return adapter.strategy.OpenNewPosition(adapter.ctx, latestPrice)
+}
+
+//SetStoploss calls the StrategyV2 with the Context
+func (adapter *strategyV2Adapter) SetStoploss(openPosition Position) *StoplossEvt {
+	return adapter.strategy.SetStoploss(adapter.ctx, openPosition)
+}
+
+//SetTakeProfit calls the StrategyV2 with the Context
+func (adapter *strategyV2Adapter) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return adapter.strategy.SetTakeProfit(adapter.ctx, openPosition)
+}
@@ -0,0 +1,184 @@
+package kate
+
+import (
+	"testing"
+	"time"
+)
+
+//contextCheckStrategy runs the simpleStrategy checking the state exposed by the context on every callback
+type contextCheckStrategy struct {
+	simple      simpleStrategy
+	t           *testing.T
+	candles     int
+	pendingSeen bool
+}
+
+func (strategy *contextCheckStrategy) PreProcessIndicators(ctx *Context, latestPrice DataPoint) {
+	if ctx.Candle() != strategy.candles || ctx.Price() != latestPrice.Close() || ctx.Time() != latestPrice.Time() ||
+		ctx.History().At(0) != latestPrice {
+		strategy.t.Fatalf("The context is not up to date on candle %v", strategy.candles)
+	}
+	strategy.candles++
+	strategy.simple.PreProcessIndicators(latestPrice)
+}
+
+func (strategy *contextCheckStrategy) OpenNewPosition(ctx *Context, latestPrice DataPoint) *OpenPositionEvt {
+	if _, open := ctx.OpenPosition(); open || ctx.Equity() != ctx.Balance() || ctx.PendingOrders() != nil {
+		strategy.t.Fatalf("The context should not have a open position when OpenNewPosition is called")
+	}
+	return strategy.simple.OpenNewPosition(latestPrice)
+}
+
+func (strategy *contextCheckStrategy) SetStoploss(ctx *Context, openPosition Position) *StoplossEvt {
+	position, open := ctx.OpenPosition()
+	if !open || position.EntryPrice != openPosition.EntryPrice {
+		strategy.t.Fatalf("The context should have the open position")
+	}
+	if pending := ctx.PendingOrders(); len(pending) == 2 && pending[0].Price == position.Stoploss &&
+		pending[1].Action == TakeProfitAction {
+		strategy.pendingSeen = true
+	}
+	return strategy.simple.SetStoploss(openPosition)
+}
+
+func (strategy *contextCheckStrategy) SetTakeProfit(ctx *Context, openPosition Position) *TakeProfitEvt {
+	return strategy.simple.SetTakeProfit(openPosition)
+}
+
+//nestedRunStrategy runs another backtester of the same adapted strategy in the middle of the run and counts the
+//candles where the context is not the one of the run
+type nestedRunStrategy struct {
+	nested *Backtester
+	stale  int
+}
+
+func (strategy *nestedRunStrategy) PreProcessIndicators(ctx *Context, latestPrice DataPoint) {
+	if ctx.Price() != latestPrice.Close() || ctx.Time() != latestPrice.Time() {
+		strategy.stale++
+	}
+	if nested := strategy.nested; nested != nil && ctx.Candle() == 10 {
+		strategy.nested = nil
+		nested.Run()
+	}
+}
+
+func (strategy *nestedRunStrategy) OpenNewPosition(ctx *Context, latestPrice DataPoint) *OpenPositionEvt {
+	return nil
+}
+
+func (strategy *nestedRunStrategy) SetStoploss(ctx *Context, openPosition Position) *StoplossEvt {
+	return nil
+}
+
+func (strategy *nestedRunStrategy) SetTakeProfit(ctx *Context, openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+//optionalV2Strategy is a StrategyV2 implementing the optional interfaces of Strategy, it opens long positions
+//once the history and a hourly candle are received and closes them after 3 candles
+type optionalV2Strategy struct {
+	history    *Series
+	timeframes int
+	candle     int
+	historyOK  bool
+}
+
+func (strategy *optionalV2Strategy) SetHistory(history *Series) {
+	strategy.history = history
+}
+
+func (strategy *optionalV2Strategy) PreProcessTimeframe(timeframe string, closedCandle DataPoint) {
+	strategy.timeframes++
+}
+
+func (strategy *optionalV2Strategy) PreProcessIndicators(ctx *Context, latestPrice DataPoint) {
+	strategy.candle = ctx.Candle()
+	strategy.historyOK = strategy.history != nil && strategy.history == ctx.History()
+}
+
+func (strategy *optionalV2Strategy) OpenNewPosition(ctx *Context, latestPrice DataPoint) *OpenPositionEvt {
+	if !strategy.historyOK || strategy.timeframes == 0 {
+		return nil
+	}
+	return &OpenPositionEvt{Direction: LONG, Leverage: 1}
+}
+
+func (strategy *optionalV2Strategy) ClosePosition(openPosition Position) bool {
+	return strategy.candle-openPosition.EntryCandle >= 3
+}
+
+func (strategy *optionalV2Strategy) SetStoploss(ctx *Context, openPosition Position) *StoplossEvt {
+	return nil
+}
+
+func (strategy *optionalV2Strategy) SetTakeProfit(ctx *Context, openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+func TestStrategyContext(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy := &contextCheckStrategy{t: t}
+	backtester := NewBacktester(WithContext(strategy), data)
+	backtester.SetBalance(1000)
+	backtester.SetFixedTradeAmount(20)
+	stats := backtester.Run()
+
+	if stats.TotalTrades != 21 || !isEqual(stats.NetProfit, -11.872800000000666) {
+		t.Errorf("The adapted strategy should have the results of the simple strategy, found %v trades and %v",
+			stats.TotalTrades, stats.NetProfit)
+	}
+	if !strategy.pendingSeen {
+		t.Errorf("The stoploss and takeprofit should be pending while the position is open")
+	}
+}
+
+func TestStrategyContextPerRun(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	other, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy := &nestedRunStrategy{}
+	adapted := WithContext(strategy)
+	strategy.nested = NewBacktester(adapted, other)
+	NewBacktester(adapted, data).Run()
+	if strategy.nested != nil {
+		t.Fatal("The nested run should have been started")
+	}
+	if strategy.stale != 0 {
+		t.Errorf("Every run should keep its own context, found %v candles with the context of another run",
+			strategy.stale)
+	}
+}
+
+func TestStrategyV2OptionalInterfaces(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	hourly, _ := data.Resample(time.Hour)
+
+	strategy := &optionalV2Strategy{}
+	backtester := NewBacktester(WithContext(strategy), data)
+	if err := backtester.AddTimeframe("1h", hourly); err != nil {
+		t.Fatal(err)
+	}
+	stats := backtester.Run()
+	if strategy.timeframes == 0 || stats.TotalTrades == 0 {
+		t.Fatalf("The history and timeframes should be delivered to the StrategyV2, found %v hourly candles and %v "+
+			"trades", strategy.timeframes, stats.TotalTrades)
+	}
+	for _, trade := range stats.Trades() {
+		if trade.ExitReason != MarketExit || trade.CloseCandle != trade.EntryCandle+3 {
+			t.Errorf("The positions should be closed by the ClosePosition of the StrategyV2, found %+v", trade)
+		}
+	}
+}
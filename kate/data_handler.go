@@ -5,10 +5,10 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 //DataHandler is a wrapper that packages the required data for running backtesting simulation.
@@ -18,6 +18,7 @@ type DataHandler struct {
 
 //Position is the representation of a traded position
 type Position struct {
+	Symbol                 string //traded pair when the position belongs to a portfolio, empty otherwise
 	Direction              Direction
 	Size                   float64 //total size of the position including leverage
 	Leverage               uint    //the multiplier for increasing the total traded position
@@ -26,13 +27,25 @@ type Position struct {
 	Stoploss, TakeProfit   float64
 	UnrealizedPNL          float64
 	RealizedPNL            float64
-	TotalFeePaid           float64
+	TotalFeePaid           float64 //sum of the maker, taker and liquidation fees
+	MakerFeePaid           float64
+	TakerFeePaid           float64
+	LiquidationFeePaid     float64
+	SlippageCost           float64 //difference between the requested and executed prices of market orders
+	FundingPaid            float64 //funding paid while the position was open, negative values denote funding received
 	LiquidationPrice       float64
+	EntryCandle            int       //index of the candle where the position was opened
+	CloseCandle            int       //index of the candle where the position was closed
+	EntryTime, CloseTime   time.Time //zero values when the price data has no timestamps
+	ExitReason             ExitReason
 }
 
 //Required columns in the CSV file
 var csvColumns = []string{"open", "high", "low", "close", "volume"}
 
+//Optional column names accepted for the unix timestamp of when each candle was closed
+var csvTimeColumns = []string{"close_time", "time", "timestamp"}
+
 //newDataHandler creates and initializes a DataHandler with pricing data and executes the required setup
 func newDataHandler(prices []DataPoint) *DataHandler {
 	return &DataHandler{
@@ -42,14 +55,20 @@ func newDataHandler(prices []DataPoint) *DataHandler {
 
 //PricesFromCSV reads all csv data in the OHLCV format to the DataHandler and returns if a error occurred
 func PricesFromCSV(csvFilePath string) (*DataHandler, error) {
-	csvFile, _ := os.Open(csvFilePath)
+	csvFile, error := os.Open(csvFilePath)
+	if error != nil {
+		return nil, error
+	}
+	defer csvFile.Close()
 	reader := csv.NewReader(bufio.NewReader(csvFile))
 
 	//Reading first line header and validating the required columns
-	if line, error := reader.Read(); error != nil || !isCSVHeaderValid(line) {
+	header, error := reader.Read()
+	if error != nil || !isCSVHeaderValid(header) {
 		return nil, fmt.Errorf(`error reading header with columns in the csv.
 				Make sure the CSV has the columns Open, High, Low, Close, Volume`)
 	}
+	hasTime := hasCSVTimeColumn(header)
 
 	var prices []DataPoint
 	for {
@@ -57,7 +76,7 @@ func PricesFromCSV(csvFilePath string) (*DataHandler, error) {
 		if error == io.EOF {
 			break
 		} else if error != nil {
-			log.Fatal(error)
+			return nil, fmt.Errorf("error reading the csv %v: %v", csvFilePath, error)
 		}
 
 		//Checking each OHLCV value in the csv
@@ -71,12 +90,20 @@ func PricesFromCSV(csvFilePath string) (*DataHandler, error) {
 
 		}
 
+		var closeTime time.Time
+		if hasTime {
+			if closeTime, error = strToTime(line[len(csvColumns)]); error != nil {
+				return nil, error
+			}
+		}
+
 		prices = append(prices, DataPoint{
-			open:   numbers[0],
-			high:   numbers[1],
-			low:    numbers[2],
-			close:  numbers[3],
-			volume: numbers[4],
+			open:      numbers[0],
+			high:      numbers[1],
+			low:       numbers[2],
+			close:     numbers[3],
+			volume:    numbers[4],
+			timestamp: closeTime,
 		})
 	}
 
@@ -93,8 +120,37 @@ func strToFloat(str string) (float64, error) {
 		Make sure the csv contain only valid float numbers`, str)
 }
 
+//strToTime converts a unix timestamp in seconds (or milliseconds) to a UTC time
+func strToTime(str string) (time.Time, error) {
+	timestamp, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(`invalid timestamp '%v' was found in the provided csv.
+		Make sure the time column contains only unix timestamps`, str)
+	}
+	if timestamp > 1e12 {
+		return time.Unix(0, timestamp*int64(time.Millisecond)).UTC(), nil
+	}
+	return time.Unix(timestamp, 0).UTC(), nil
+}
+
+//hasCSVTimeColumn checks if the column after the OHLCV columns contains the candle timestamps
+func hasCSVTimeColumn(header []string) bool {
+	if len(header) <= len(csvColumns) {
+		return false
+	}
+	for _, column := range csvTimeColumns {
+		if strings.ToLower(header[len(csvColumns)]) == column {
+			return true
+		}
+	}
+	return false
+}
+
 //Check if the first line with columns of the csv are in the valid format
 func isCSVHeaderValid(firstLine []string) bool {
+	if len(firstLine) < len(csvColumns) {
+		return false
+	}
 	for i, column := range csvColumns {
 		if strings.ToLower(firstLine[i]) != column {
 			return false
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadCSVData(t *testing.T) {
@@ -52,6 +53,27 @@ func TestLoadCSVData(t *testing.T) {
 
 }
 
+func TestLoadCSVTimestamps(t *testing.T) {
+	handler, err := PricesFromCSV("../testdata/ETHUSD1.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	firstTime := time.Date(2021, time.April, 11, 21, 44, 0, 0, time.UTC)
+	if !handler.Prices[0].Time().Equal(firstTime) {
+		t.Errorf("The time of the first price is %v the expected was %v", handler.Prices[0].Time(), firstTime)
+	}
+
+	if elapsed := handler.Prices[1].Time().Sub(handler.Prices[0].Time()); elapsed != time.Minute {
+		t.Errorf("The time between the first candles is %v the expected was %v", elapsed, time.Minute)
+	}
+
+	withoutTime, _ := PricesFromCSV("../testdata/simple_example.csv")
+	if !withoutTime.Prices[0].Time().IsZero() {
+		t.Errorf("A csv without the time column should not contain timestamps, found %v", withoutTime.Prices[0].Time())
+	}
+}
+
 func TestLoadInvalidCSV(t *testing.T) {
 	columnsCSV := []string{"ERROR,Open,High,Low,Close,Volume", "Open,ERROR,High,Low,Close,Volume",
 		"Open,High,ERROR,Low,Close,Volume", "Open,High,Low,ERROR,Close,Volume", "Open,High,Low,Close,ERROR,Volume",
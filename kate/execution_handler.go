@@ -2,6 +2,8 @@ package kate
 
 import (
 	"fmt"
+	"math"
+	"time"
 )
 
 //ExchangeHandler emulates to behavior of a crypto exchange accepting and tracking orders/trades.
@@ -14,8 +16,15 @@ type ExchangeHandler struct {
 	amountPerTrade   float64 //Percentage (0.01 = 1%) of the balance used to trade each individual single position.
 	openPosition     *Position
 	tradeHistory     []*Position
+	orderHistory     []*Order
 	currentPrice     float64 //price used as reference for latest price data - used to check if inputs are valid
+	currentCandle    int     //index of the latest price data received
+	currentTime      time.Time
 	fixedTradeAmount float64 //amount if define that will be used in all trades
+	fundingRate      float64 //Funding rate paid by longs to shorts on each funding interval - 0.01 = 1%
+	fundingInterval  time.Duration
+	filled           func(order Order)       //optional notification of the orders filled
+	closed           func(position Position) //optional notification of the positions closed
 }
 
 //MarketType is a type of market that can be traded ( USDFutures, CoinMarginedFutures, Spot, ...)
@@ -46,15 +55,28 @@ const (
 func NewExchangeHandler(market MarketType, makerFeePercent, takerFeePercent, percentagePerTrade float64) *ExchangeHandler {
 	handler := &ExchangeHandler{
 		balance:        1000,
-		slippage:       0.0002,
+		slippage:       0,
 		makerFee:       makerFeePercent / 100,
 		takerFee:       takerFeePercent / 100,
 		amountPerTrade: percentagePerTrade / 100,
+		currentCandle:  -1,
 	}
 	handler.marketHandler = newMarketHandler(market, handler.makerFee, handler.takerFee)
 	return handler
 }
 
+//reset returns a copy of the exchange configuration without positions, orders or price data
+func (handler *ExchangeHandler) reset() *ExchangeHandler {
+	fresh := *handler
+	fresh.openPosition = nil
+	fresh.tradeHistory = nil
+	fresh.orderHistory = nil
+	fresh.currentPrice = 0
+	fresh.currentCandle = -1
+	fresh.currentTime = time.Time{}
+	return &fresh
+}
+
 //SetBalance sets the balance that will be used to trade
 func (handler *ExchangeHandler) SetBalance(amount float64) {
 	handler.balance = amount
@@ -63,75 +85,193 @@ func (handler *ExchangeHandler) SetBalance(amount float64) {
 //SetSlipage defines the slipage in the price on all orders of type market
 func (handler *ExchangeHandler) SetSlipage(slipagePercent float64) {
 	handler.slippage = slipagePercent / 100
-	//TODO: implement logic to apply the slipagge when opening executing (open/close) market orders
+}
+
+//SetFundingRate defines the funding rate charged from open positions on every interval of time.
+//A positive rate is paid by long positions and received by short positions, funding requires price data with timestamps
+func (handler *ExchangeHandler) SetFundingRate(ratePercent float64, interval time.Duration) {
+	handler.fundingRate = ratePercent / 100
+	handler.fundingInterval = interval
 }
 
 //OpenMarketOrder opens a new position with a market order if there is no positions already opened
 func (handler *ExchangeHandler) OpenMarketOrder(tradeDirection Direction, leverage uint) error {
+	return handler.openMarketOrder(tradeDirection, leverage, 0)
+}
+
+//openMarketOrder opens a position using the amount of the balance requested,
+//a amount of zero uses the fixed trade amount or the percentage of the balance per trade
+func (handler *ExchangeHandler) openMarketOrder(tradeDirection Direction, leverage uint, amount float64) error {
+	order := handler.newOrder(OpenAction, MARKET, tradeDirection, leverage)
 	if handler.openPosition != nil {
-		return fmt.Errorf("there is a position already opened")
+		return handler.rejectOrder(order, fmt.Errorf("there is a position already opened"))
 	}
 
 	if handler.balance <= 1 {
-		return fmt.Errorf("no more balance to trade")
+		return handler.rejectOrder(order, fmt.Errorf("no more balance to trade"))
 	}
 
-	amountToTrade := handler.balance * handler.amountPerTrade
-	if handler.fixedTradeAmount > 0 {
-		amountToTrade = handler.fixedTradeAmount
+	amountToTrade := handler.amountToTrade(amount)
+	if amountToTrade > handler.balance {
+		return handler.rejectOrder(order, fmt.Errorf("the amount to trade %v exceeds the balance %v", amountToTrade, handler.balance))
 	}
 
-	handler.openPosition = handler.marketHandler.createPosition(tradeDirection, handler.currentPrice,
+	entryPrice := handler.slippedPrice(handler.currentPrice, tradeDirection == LONG)
+	handler.openPosition = handler.marketHandler.createPosition(tradeDirection, entryPrice,
 		handler.balance, amountToTrade, leverage)
-	handler.openPosition.TotalFeePaid = handler.fee(TakerTransition)
+	handler.openPosition.SlippageCost = handler.openPosition.Size * math.Abs(entryPrice-handler.currentPrice)
+	handler.chargeFee(TakerTransition)
+	handler.openPosition.EntryCandle = handler.currentCandle
+	handler.openPosition.EntryTime = handler.currentTime
+
+	order.Price = handler.openPosition.EntryPrice
+	handler.orderHistory = append(handler.orderHistory, order)
+	if handler.filled != nil {
+		handler.filled(*order)
+	}
+	return nil
+}
+
+//CloseMarketOrder closes the open position with a market order at the latest price
+func (handler *ExchangeHandler) CloseMarketOrder() error {
+	return handler.closeMarketOrder(MarketExit)
+}
+
+//closeMarketOrder closes the open position at the latest price with the reason
+func (handler *ExchangeHandler) closeMarketOrder(reason ExitReason) error {
+	position := handler.openPosition
+	if position == nil {
+		return handler.rejectOrder(handler.newOrder(CloseAction, MARKET, LONG, 0),
+			fmt.Errorf("there is no positions open to close"))
+	}
+
+	order := handler.newOrder(CloseAction, MARKET, position.Direction, position.Leverage)
+	order.Price = handler.slippedPrice(handler.currentPrice, position.Direction == SHORT)
+	position.SlippageCost += position.Size * math.Abs(order.Price-handler.currentPrice)
+	handler.orderHistory = append(handler.orderHistory, order)
+	if handler.filled != nil {
+		handler.filled(*order)
+	}
+	handler.closePosition(order.Price, TakerTransition, reason)
 	return nil
 }
 
 //SetStoploss defines a stoploss that closes the open position completely when the price is reached.
-//The stoploss triggered is a market order
+//The stoploss triggered is a market order, a order is only recorded when the price of the stoploss changes
 func (handler *ExchangeHandler) SetStoploss(price float64) error {
+	order := handler.newTriggerOrder(StoplossAction, MARKET, price)
 	if handler.openPosition == nil {
-		return fmt.Errorf("there is no positions open to set a stoploss")
+		return handler.rejectOrder(order, fmt.Errorf("there is no positions open to set a stoploss"))
+	}
+
+	if handler.openPosition.Stoploss == price {
+		return nil
 	}
 
 	if handler.openPosition.Direction == LONG && price > handler.currentPrice {
-		return fmt.Errorf("the stoploss must be lower than the current price for long positions")
+		return handler.rejectOrder(order, fmt.Errorf("the stoploss must be lower than the current price for long positions"))
 	}
 
 	if handler.openPosition.Direction == SHORT && price < handler.currentPrice {
-		return fmt.Errorf("the stoploss must be higher than the current price for short positions")
+		return handler.rejectOrder(order, fmt.Errorf("the stoploss must be higher than the current price for short positions"))
 	}
 
 	handler.openPosition.Stoploss = price
+	handler.orderHistory = append(handler.orderHistory, order)
 	return nil
 }
 
-//SetTakeProfit defines a new takeprofit for the current open position
+//SetTakeProfit defines a new takeprofit for the current open position,
+//a order is only recorded when the price of the takeprofit changes
 func (handler *ExchangeHandler) SetTakeProfit(price float64) error {
+	order := handler.newTriggerOrder(TakeProfitAction, LIMIT, price)
 	if handler.openPosition == nil {
-		return fmt.Errorf("there is no positions open to set a takeprofit")
+		return handler.rejectOrder(order, fmt.Errorf("there is no positions open to set a takeprofit"))
+	}
+
+	if handler.openPosition.TakeProfit == price {
+		return nil
 	}
 
 	if handler.openPosition.Direction == LONG && price < handler.currentPrice {
-		return fmt.Errorf("the takeprofit must be higher than the current price for long positions")
+		return handler.rejectOrder(order, fmt.Errorf("the takeprofit must be higher than the current price for long positions"))
 	}
 
 	if handler.openPosition.Direction == SHORT && price > handler.currentPrice {
-		return fmt.Errorf("the takeprofit must be lower than the current price for short positions")
+		return handler.rejectOrder(order, fmt.Errorf("the takeprofit must be lower than the current price for short positions"))
 	}
 
 	handler.openPosition.TakeProfit = price
+	handler.orderHistory = append(handler.orderHistory, order)
 	return nil
 }
 
+//newOrder creates the record of a order submitted at the latest price data
+func (handler *ExchangeHandler) newOrder(action OrderAction, orderType OrderType, direction Direction, leverage uint) *Order {
+	return &Order{
+		Candle:    handler.currentCandle,
+		Time:      handler.currentTime,
+		Action:    action,
+		Type:      orderType,
+		Direction: direction,
+		Leverage:  leverage,
+		Status:    Filled,
+	}
+}
+
+//newTriggerOrder creates the record of a stoploss/takeprofit order for the open position
+func (handler *ExchangeHandler) newTriggerOrder(action OrderAction, orderType OrderType, price float64) *Order {
+	order := handler.newOrder(action, orderType, LONG, 0)
+	if handler.openPosition != nil {
+		order.Direction = handler.openPosition.Direction
+		order.Leverage = handler.openPosition.Leverage
+	}
+	order.Price = price
+	order.Status = Placed
+	return order
+}
+
+//rejectOrder records a order refused by the exchange and returns the reason
+func (handler *ExchangeHandler) rejectOrder(order *Order, reason error) error {
+	order.Status = Rejected
+	order.Error = reason.Error()
+	handler.orderHistory = append(handler.orderHistory, order)
+	return reason
+}
+
+//amountToTrade is the amount of the balance used to open a position, the requested amount when it is positive
+//or else the fixed trade amount or the percentage of the balance per trade
+func (handler *ExchangeHandler) amountToTrade(requested float64) float64 {
+	if requested > 0 {
+		return requested
+	}
+	if handler.fixedTradeAmount > 0 {
+		return handler.fixedTradeAmount
+	}
+	return handler.balance * handler.amountPerTrade
+}
+
+//usedMargin is the margin of the open position in the currency of the balance, zero when there is none
+func (handler *ExchangeHandler) usedMargin() float64 {
+	if handler.openPosition == nil {
+		return 0
+	}
+	return handler.marketHandler.notional(handler.openPosition.Margin, handler.openPosition.EntryPrice)
+}
+
 //OnPriceChange emulates the price change for the asset.
 //Positions may be closed by: take profit, stoploss or liquidations.
 func (handler *ExchangeHandler) onPriceChange(newPrice OHLCV) {
+	previousTime := handler.currentTime
 	handler.currentPrice = newPrice.Close()
+	handler.currentTime = newPrice.Time()
+	handler.currentCandle++
 	if handler.openPosition == nil {
 		return
 	}
 
+	handler.chargeFunding(previousTime, newPrice.Close())
+
 	if handler.checkCloseLongs(newPrice) || handler.checkCloseShorts(newPrice) ||
 		handler.checkLiquidation(newPrice) {
 		return //Position closed successfully
@@ -139,6 +279,15 @@ func (handler *ExchangeHandler) onPriceChange(newPrice OHLCV) {
 	handler.updateUnrealizedPNL(newPrice.Close())
 }
 
+//equity is the balance including the result of closing the open position at the latest price
+func (handler *ExchangeHandler) equity() float64 {
+	if handler.openPosition == nil {
+		return handler.balance
+	}
+	return handler.balance + handler.openPosition.UnrealizedPNL - handler.openPosition.TotalFeePaid -
+		handler.openPosition.FundingPaid
+}
+
 func (handler *ExchangeHandler) updateUnrealizedPNL(latestPrice float64) {
 	handler.openPosition.UnrealizedPNL = handler.marketHandler.unrealizedPNL(handler.openPosition, latestPrice)
 }
@@ -149,12 +298,13 @@ func (handler *ExchangeHandler) checkCloseShorts(newPrice OHLCV) bool {
 	}
 
 	if handler.openPosition.TakeProfit > 0 && newPrice.Low() <= handler.openPosition.TakeProfit {
-		handler.closePosition(handler.openPosition.TakeProfit, MakerTransition)
+		handler.closePosition(handler.openPosition.TakeProfit, MakerTransition, TakeProfitExit)
 		return true
 	}
 
 	if handler.openPosition.Stoploss > 0 && newPrice.High() >= handler.openPosition.Stoploss {
-		handler.closePosition(handler.openPosition.Stoploss, TakerTransition)
+		handler.closePosition(handler.slippedPrice(handler.openPosition.Stoploss, handler.openPosition.Direction == SHORT),
+			TakerTransition, StoplossExit)
 		return true
 	}
 	return false
@@ -166,44 +316,113 @@ func (handler *ExchangeHandler) checkCloseLongs(newPrice OHLCV) bool {
 	}
 
 	if handler.openPosition.TakeProfit > 0 && newPrice.High() >= handler.openPosition.TakeProfit {
-		handler.closePosition(handler.openPosition.TakeProfit, MakerTransition)
+		handler.closePosition(handler.openPosition.TakeProfit, MakerTransition, TakeProfitExit)
 		return true
 	}
 
 	if handler.openPosition.Stoploss > 0 && newPrice.Low() <= handler.openPosition.Stoploss {
-		handler.closePosition(handler.openPosition.Stoploss, TakerTransition)
+		handler.closePosition(handler.slippedPrice(handler.openPosition.Stoploss, handler.openPosition.Direction == SHORT),
+			TakerTransition, StoplossExit)
 		return true
 	}
 	return false
 }
 
-func (handler *ExchangeHandler) closePosition(closePrice float64, transition PositionTransition) {
+func (handler *ExchangeHandler) closePosition(closePrice float64, transition PositionTransition, reason ExitReason) {
+	if reason == StoplossExit {
+		handler.openPosition.SlippageCost += handler.openPosition.Size * math.Abs(closePrice-handler.openPosition.Stoploss)
+	}
 	handler.updateUnrealizedPNL(closePrice)
 	handler.openPosition.ClosePrice = closePrice
-	handler.openPosition.TotalFeePaid += handler.fee(transition)
-	handler.openPosition.RealizedPNL = handler.openPosition.UnrealizedPNL - handler.openPosition.TotalFeePaid
+	handler.openPosition.CloseCandle = handler.currentCandle
+	handler.openPosition.CloseTime = handler.currentTime
+	handler.openPosition.ExitReason = reason
+	handler.chargeFee(transition)
+	handler.openPosition.RealizedPNL = handler.openPosition.UnrealizedPNL - handler.openPosition.TotalFeePaid -
+		handler.openPosition.FundingPaid
 	handler.openPosition.UnrealizedPNL = 0
 	handler.balance += handler.openPosition.RealizedPNL
 
 	handler.tradeHistory = append(handler.tradeHistory, handler.openPosition)
+	handler.notifyClose(closePrice, reason)
 
 	handler.openPosition = nil
 }
 
+//notifyClose records the execution of the stoploss/takeprofit order and reports it with the closed position
+func (handler *ExchangeHandler) notifyClose(closePrice float64, reason ExitReason) {
+	if reason == StoplossExit || reason == TakeProfitExit {
+		action, orderType := TakeProfitAction, LIMIT
+		if reason == StoplossExit {
+			action, orderType = StoplossAction, MARKET
+		}
+		execution := handler.newOrder(action, orderType, handler.openPosition.Direction, handler.openPosition.Leverage)
+		execution.Price = closePrice
+		handler.orderHistory = append(handler.orderHistory, execution)
+		if handler.filled != nil {
+			handler.filled(*execution)
+		}
+	}
+	if handler.closed != nil {
+		handler.closed(*handler.openPosition)
+	}
+}
+
 //checkLiquidation verifies if a open position should be liquidated
 func (handler *ExchangeHandler) checkLiquidation(newPrice OHLCV) bool {
 	if handler.openPosition.Direction == LONG && handler.openPosition.LiquidationPrice >= newPrice.Low() {
-		handler.closePosition(handler.openPosition.LiquidationPrice, Liquidation)
+		handler.closePosition(handler.openPosition.LiquidationPrice, Liquidation, LiquidationExit)
 		return true
 	}
 
 	if handler.openPosition.Direction == SHORT && handler.openPosition.LiquidationPrice <= newPrice.High() {
-		handler.closePosition(handler.openPosition.LiquidationPrice, Liquidation)
+		handler.closePosition(handler.openPosition.LiquidationPrice, Liquidation, LiquidationExit)
 		return true
 	}
 	return false
 }
 
+//chargeFee applies the fee of the transition to the open position keeping track of the kind of fee paid
+func (handler *ExchangeHandler) chargeFee(transition PositionTransition) {
+	fee := handler.fee(transition)
+	switch transition {
+	case MakerTransition:
+		handler.openPosition.MakerFeePaid += fee
+	case TakerTransition:
+		handler.openPosition.TakerFeePaid += fee
+	case Liquidation:
+		handler.openPosition.LiquidationFeePaid += fee
+	}
+	handler.openPosition.TotalFeePaid += fee
+}
+
+//slippedPrice applies the slippage to the price of a market order making buys more expensive and sells cheaper
+func (handler *ExchangeHandler) slippedPrice(price float64, buying bool) float64 {
+	if buying {
+		return price * (1 + handler.slippage)
+	}
+	return price * (1 - handler.slippage)
+}
+
+//chargeFunding charges the open position for every funding time reached since the previous price data
+func (handler *ExchangeHandler) chargeFunding(previousTime time.Time, price float64) {
+	if handler.fundingRate == 0 || handler.fundingInterval <= 0 || previousTime.IsZero() {
+		return
+	}
+
+	interval := int64(handler.fundingInterval)
+	fundings := handler.currentTime.UnixNano()/interval - previousTime.UnixNano()/interval
+	if fundings <= 0 {
+		return
+	}
+
+	payment := float64(fundings) * handler.marketHandler.funding(handler.openPosition, price, handler.fundingRate)
+	if handler.openPosition.Direction == SHORT {
+		payment = -payment
+	}
+	handler.openPosition.FundingPaid += payment
+}
+
 func (handler *ExchangeHandler) fee(transition PositionTransition) float64 {
 	switch transition {
 	case MakerTransition:
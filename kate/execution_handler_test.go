@@ -3,6 +3,7 @@ package kate
 import (
 	"math"
 	"testing"
+	"time"
 )
 
 const maxError = 0.001
@@ -98,3 +99,95 @@ func isEqual(x, y float64) bool {
 func CreateData(value float64) OHLCV {
 	return DataPoint{open: value, high: value, low: value, close: value, volume: value}
 }
+
+func TestCostAttribution(t *testing.T) {
+	start := time.Date(2021, time.March, 1, 7, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		direction          Direction
+		slippage           float64
+		fundingRate        float64
+		closePrice         OHLCV
+		takeProfit         float64
+		stoploss           float64
+		expectedReason     ExitReason
+		expectedMakerFee   float64
+		expectedTakerFee   float64
+		expectedLiquidFee  float64
+		expectedSlippage   float64
+		expectedFunding    float64
+		expectedRealizedPL float64
+	}{
+		//size 2 opened at 1000 and closed by the takeprofit (maker) at 1100
+		{LONG, 0, 0, NewDataPoint(1100, 1100, 1100, 1100, 1, start.Add(time.Hour)), 1100, 900, TakeProfitExit,
+			0.44, 0.8, 0, 0, 0, 198.76},
+		//size 1.998 opened at 1001 with slippage and closed by the stoploss at 900 executed at 899.1
+		//after paying the funding at 08:00 (price 1000) and 16:00 (price 890)
+		{LONG, 0.1, 0.01, NewDataPoint(890, 890, 890, 890, 1, start.Add(10*time.Hour)), 1100, 900, StoplossExit,
+			0, 0.8 + 0.718561, 0, 1.998002 * 1.9, 1.998002 * 0.189, -203.5964 - 1.518561 - 0.377622},
+		//size 2 short receives the funding at 08:00 (price 1000) and 16:00 (price 1200) and is liquidated at 1095
+		{SHORT, 0, 0.01, NewDataPoint(1200, 1200, 1200, 1200, 1, start.Add(10*time.Hour)), 0, 0, LiquidationExit,
+			0, 0.8, 1.752, 0, -0.44, -190 - 0.8 - 1.752 + 0.44},
+	}
+
+	for _, test := range tests {
+		handler := NewExchangeHandler(USDFutures, 0.020, 0.040, 20)
+		handler.SetBalance(1000)
+		handler.SetSlipage(test.slippage)
+		handler.SetFundingRate(test.fundingRate, 8*time.Hour)
+		handler.onPriceChange(NewDataPoint(1000, 1000, 1000, 1000, 1, start))
+
+		handler.OpenMarketOrder(test.direction, 10)
+		if test.takeProfit > 0 {
+			handler.SetTakeProfit(test.takeProfit)
+			handler.SetStoploss(test.stoploss)
+		}
+		if test.fundingRate != 0 {
+			handler.onPriceChange(NewDataPoint(1000, 1000, 1000, 1000, 1, start.Add(8*time.Hour+time.Minute)))
+		}
+		handler.onPriceChange(test.closePrice)
+
+		if len(handler.tradeHistory) != 1 {
+			t.Fatalf("The position didnt close properly")
+		}
+		trade := handler.tradeHistory[0]
+		if trade.ExitReason != test.expectedReason || !isEqual(trade.MakerFeePaid, test.expectedMakerFee) ||
+			!isEqual(trade.TakerFeePaid, test.expectedTakerFee) || !isEqual(trade.LiquidationFeePaid, test.expectedLiquidFee) ||
+			!isEqual(trade.SlippageCost, test.expectedSlippage) || !isEqual(trade.FundingPaid, test.expectedFunding) ||
+			!isEqual(trade.RealizedPNL, test.expectedRealizedPL) ||
+			!isEqual(trade.TotalFeePaid, trade.MakerFeePaid+trade.TakerFeePaid+trade.LiquidationFeePaid) {
+			t.Errorf("The costs of the %v trade closed by %v are wrong: %+v", test.direction, test.expectedReason, trade)
+		}
+	}
+}
+
+func TestRepeatedTriggerOrders(t *testing.T) {
+	var tests = []struct {
+		direction      Direction
+		stoplosses     []float64
+		takeProfits    []float64
+		expectedOrders int
+	}{
+		{LONG, []float64{900, 900, 900}, []float64{1100, 1100}, 3},
+		{LONG, []float64{900, 950, 950}, []float64{1100}, 4},
+		{SHORT, []float64{1100, 1100}, []float64{900, 850, 850, 800}, 5},
+		//the rejected stoploss is recorded every time it is requested
+		{SHORT, []float64{950, 950}, nil, 3},
+	}
+
+	for _, test := range tests {
+		handler := NewExchangeHandler(USDFutures, 0.020, 0.040, 20)
+		handler.onPriceChange(CreateData(1000))
+		handler.OpenMarketOrder(test.direction, 10)
+		for _, stoploss := range test.stoplosses {
+			handler.SetStoploss(stoploss)
+		}
+		for _, takeProfit := range test.takeProfits {
+			handler.SetTakeProfit(takeProfit)
+		}
+
+		if len(handler.orderHistory) != test.expectedOrders {
+			t.Errorf("Expected %v orders setting the stoplosses %v and takeprofits %v, found %v", test.expectedOrders,
+				test.stoplosses, test.takeProfits, len(handler.orderHistory))
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package kate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+//Columns written on each exported file, new columns are only appended to keep the schema stable
+var (
+	tradeColumns = []string{"entry_candle", "close_candle", "entry_time", "close_time", "direction", "leverage",
+		"size", "margin", "entry_price", "close_price", "stoploss", "takeprofit", "liquidation_price",
+		"fee_paid", "realized_pnl", "exit_reason", "maker_fee", "taker_fee", "liquidation_fee", "slippage_cost",
+		"funding_paid"}
+	orderColumns   = []string{"candle", "time", "action", "type", "direction", "leverage", "price", "status", "error"}
+	equityColumns  = []string{"candle", "time", "balance", "equity", "drawdown"}
+	summaryColumns = []string{"initial_balance", "final_balance", "net_profit", "roi_percentage", "sharpe_ratio",
+		"win_rate", "max_drawdown", "total_trades", "total_data_points", "liquidations", "liquidation_loss",
+		"maker_fees", "taker_fees", "liquidation_fees", "slippage_cost", "funding_paid", "total_costs"}
+	periodColumns       = []string{"period", "start", "return", "net_profit", "trades", "win_rate", "max_drawdown"}
+	sessionColumns      = []string{"breakdown", "session", "trades", "win_rate", "net_profit", "average_return"}
+	interventionColumns = []string{"candle", "time", "rule", "action", "detail"}
+)
+
+//number is a float that is exported as null in json when it is not a valid number (NaN, Inf)
+type number float64
+
+//MarshalJSON encodes the number making sure the output is always valid json
+func (n number) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(n)) || math.IsInf(float64(n), 0) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(n))
+}
+
+type tradeRecord struct {
+	EntryCandle      int    `json:"entry_candle"`
+	CloseCandle      int    `json:"close_candle"`
+	EntryTime        string `json:"entry_time"`
+	CloseTime        string `json:"close_time"`
+	Direction        string `json:"direction"`
+	Leverage         uint   `json:"leverage"`
+	Size             number `json:"size"`
+	Margin           number `json:"margin"`
+	EntryPrice       number `json:"entry_price"`
+	ClosePrice       number `json:"close_price"`
+	Stoploss         number `json:"stoploss"`
+	TakeProfit       number `json:"takeprofit"`
+	LiquidationPrice number `json:"liquidation_price"`
+	FeePaid          number `json:"fee_paid"`
+	RealizedPNL      number `json:"realized_pnl"`
+	ExitReason       string `json:"exit_reason"`
+	MakerFee         number `json:"maker_fee"`
+	TakerFee         number `json:"taker_fee"`
+	LiquidationFee   number `json:"liquidation_fee"`
+	SlippageCost     number `json:"slippage_cost"`
+	FundingPaid      number `json:"funding_paid"`
+}
+
+type orderRecord struct {
+	Candle    int    `json:"candle"`
+	Time      string `json:"time"`
+	Action    string `json:"action"`
+	Type      string `json:"type"`
+	Direction string `json:"direction"`
+	Leverage  uint   `json:"leverage"`
+	Price     number `json:"price"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+}
+
+type interventionRecord struct {
+	Candle int    `json:"candle"`
+	Time   string `json:"time"`
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Detail string `json:"detail"`
+}
+
+type equityRecord struct {
+	Candle   int    `json:"candle"`
+	Time     string `json:"time"`
+	Balance  number `json:"balance"`
+	Equity   number `json:"equity"`
+	Drawdown number `json:"drawdown"`
+}
+
+type periodRecord struct {
+	Period      string `json:"period"`
+	Start       string `json:"start"`
+	Return      number `json:"return"`
+	NetProfit   number `json:"net_profit"`
+	Trades      int    `json:"trades"`
+	WinRate     number `json:"win_rate"`
+	MaxDrawdown number `json:"max_drawdown"`
+}
+
+type sessionRecord struct {
+	Breakdown     string `json:"breakdown"`
+	Session       string `json:"session"`
+	Trades        int    `json:"trades"`
+	WinRate       number `json:"win_rate"`
+	NetProfit     number `json:"net_profit"`
+	AverageReturn number `json:"average_return"`
+}
+
+type summaryRecord struct {
+	InitialBalance  number `json:"initial_balance"`
+	FinalBalance    number `json:"final_balance"`
+	NetProfit       number `json:"net_profit"`
+	ROIPercentage   number `json:"roi_percentage"`
+	SharpeRatio     number `json:"sharpe_ratio"`
+	WinRate         number `json:"win_rate"`
+	MaxDrawdown     number `json:"max_drawdown"`
+	TotalTrades     int    `json:"total_trades"`
+	TotalDataPoints int    `json:"total_data_points"`
+	Liquidations    int    `json:"liquidations"`
+	LiquidationLoss number `json:"liquidation_loss"`
+	MakerFees       number `json:"maker_fees"`
+	TakerFees       number `json:"taker_fees"`
+	LiquidationFees number `json:"liquidation_fees"`
+	SlippageCost    number `json:"slippage_cost"`
+	FundingPaid     number `json:"funding_paid"`
+	TotalCosts      number `json:"total_costs"`
+}
+
+//ExportCSV writes the trades, orders, equity curve, summary, periodic returns, session breakdowns and risk
+//interventions of the run as trades.csv, orders.csv, equity.csv, summary.csv, returns.csv, sessions.csv and
+//interventions.csv inside the provided directory
+func (stats *Statistics) ExportCSV(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	trades := stats.tradeRecords()
+	tradeRows := make([][]string, 0, len(trades))
+	for _, trade := range trades {
+		tradeRows = append(tradeRows, []string{itoa(trade.EntryCandle), itoa(trade.CloseCandle), trade.EntryTime,
+			trade.CloseTime, trade.Direction, itoa(int(trade.Leverage)), ftoa(trade.Size), ftoa(trade.Margin),
+			ftoa(trade.EntryPrice), ftoa(trade.ClosePrice), ftoa(trade.Stoploss), ftoa(trade.TakeProfit),
+			ftoa(trade.LiquidationPrice), ftoa(trade.FeePaid), ftoa(trade.RealizedPNL), trade.ExitReason,
+			ftoa(trade.MakerFee), ftoa(trade.TakerFee), ftoa(trade.LiquidationFee), ftoa(trade.SlippageCost),
+			ftoa(trade.FundingPaid)})
+	}
+
+	orders := stats.orderRecords()
+	orderRows := make([][]string, 0, len(orders))
+	for _, order := range orders {
+		orderRows = append(orderRows, []string{itoa(order.Candle), order.Time, order.Action, order.Type,
+			order.Direction, itoa(int(order.Leverage)), ftoa(order.Price), order.Status, order.Error})
+	}
+
+	equity := stats.equityRecords()
+	equityRows := make([][]string, 0, len(equity))
+	for _, point := range equity {
+		equityRows = append(equityRows, []string{itoa(point.Candle), point.Time, ftoa(point.Balance),
+			ftoa(point.Equity), ftoa(point.Drawdown)})
+	}
+
+	summaryRows := [][]string{stats.summaryRecord().row()}
+
+	periods := stats.periodRecords()
+	periodRows := make([][]string, 0, len(periods))
+	for _, period := range periods {
+		periodRows = append(periodRows, []string{period.Period, period.Start, ftoa(period.Return),
+			ftoa(period.NetProfit), itoa(period.Trades), ftoa(period.WinRate), ftoa(period.MaxDrawdown)})
+	}
+
+	sessions := stats.sessionRecords()
+	sessionRows := make([][]string, 0, len(sessions))
+	for _, session := range sessions {
+		sessionRows = append(sessionRows, []string{session.Breakdown, session.Session, itoa(session.Trades),
+			ftoa(session.WinRate), ftoa(session.NetProfit), ftoa(session.AverageReturn)})
+	}
+
+	interventions := stats.interventionRecords()
+	interventionRows := make([][]string, 0, len(interventions))
+	for _, intervention := range interventions {
+		interventionRows = append(interventionRows, []string{itoa(intervention.Candle), intervention.Time,
+			intervention.Rule, intervention.Action, intervention.Detail})
+	}
+
+	files := []struct {
+		name    string
+		columns []string
+		rows    [][]string
+	}{
+		{"trades.csv", tradeColumns, tradeRows},
+		{"orders.csv", orderColumns, orderRows},
+		{"equity.csv", equityColumns, equityRows},
+		{"summary.csv", summaryColumns, summaryRows},
+		{"returns.csv", periodColumns, periodRows},
+		{"sessions.csv", sessionColumns, sessionRows},
+		{"interventions.csv", interventionColumns, interventionRows},
+	}
+
+	for _, file := range files {
+		if err := writeCSV(filepath.Join(dir, file.name), file.columns, file.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ExportJSON writes the trades, orders, equity curve, summary, periodic returns, session breakdowns and risk
+//interventions of the run as trades.json, orders.json, equity.json, summary.json, returns.json, sessions.json and
+//interventions.json inside the provided directory
+func (stats *Statistics) ExportJSON(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"trades.json", stats.tradeRecords()},
+		{"orders.json", stats.orderRecords()},
+		{"equity.json", stats.equityRecords()},
+		{"summary.json", stats.summaryRecord()},
+		{"returns.json", stats.periodRecords()},
+		{"sessions.json", stats.sessionRecords()},
+		{"interventions.json", stats.interventionRecords()},
+	}
+
+	for _, file := range files {
+		if err := writeJSON(filepath.Join(dir, file.name), file.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (stats *Statistics) tradeRecords() []tradeRecord {
+	records := make([]tradeRecord, 0, len(stats.trades))
+	for _, trade := range stats.trades {
+		records = append(records, newTradeRecord(trade))
+	}
+	return records
+}
+
+func newTradeRecord(trade Position) tradeRecord {
+	return tradeRecord{
+		EntryCandle:      trade.EntryCandle,
+		CloseCandle:      trade.CloseCandle,
+		EntryTime:        formatTime(trade.EntryTime),
+		CloseTime:        formatTime(trade.CloseTime),
+		Direction:        trade.Direction.String(),
+		Leverage:         trade.Leverage,
+		Size:             number(trade.Size),
+		Margin:           number(trade.Margin),
+		EntryPrice:       number(trade.EntryPrice),
+		ClosePrice:       number(trade.ClosePrice),
+		Stoploss:         number(trade.Stoploss),
+		TakeProfit:       number(trade.TakeProfit),
+		LiquidationPrice: number(trade.LiquidationPrice),
+		FeePaid:          number(trade.TotalFeePaid),
+		RealizedPNL:      number(trade.RealizedPNL),
+		ExitReason:       trade.ExitReason.String(),
+		MakerFee:         number(trade.MakerFeePaid),
+		TakerFee:         number(trade.TakerFeePaid),
+		LiquidationFee:   number(trade.LiquidationFeePaid),
+		SlippageCost:     number(trade.SlippageCost),
+		FundingPaid:      number(trade.FundingPaid),
+	}
+}
+
+func (stats *Statistics) orderRecords() []orderRecord {
+	records := make([]orderRecord, 0, len(stats.orders))
+	for _, order := range stats.orders {
+		records = append(records, newOrderRecord(order))
+	}
+	return records
+}
+
+func newOrderRecord(order Order) orderRecord {
+	return orderRecord{
+		Candle:    order.Candle,
+		Time:      formatTime(order.Time),
+		Action:    order.Action.String(),
+		Type:      order.Type.String(),
+		Direction: order.Direction.String(),
+		Leverage:  order.Leverage,
+		Price:     number(order.Price),
+		Status:    order.Status.String(),
+		Error:     order.Error,
+	}
+}
+
+func (stats *Statistics) interventionRecords() []interventionRecord {
+	records := make([]interventionRecord, 0, len(stats.interventions))
+	for _, intervention := range stats.interventions {
+		records = append(records, interventionRecord{
+			Candle: intervention.Candle,
+			Time:   formatTime(intervention.Time),
+			Rule:   intervention.Rule,
+			Action: intervention.Action.String(),
+			Detail: intervention.Detail,
+		})
+	}
+	return records
+}
+
+func (stats *Statistics) equityRecords() []equityRecord {
+	records := make([]equityRecord, 0, len(stats.equityCurve))
+	peak := 0.0
+	for _, point := range stats.equityCurve {
+		peak = math.Max(peak, point.Equity)
+		drawdown := 0.0
+		if peak > 0 {
+			drawdown = (peak - point.Equity) / peak
+		}
+		records = append(records, equityRecord{
+			Candle:   point.Candle,
+			Time:     formatTime(point.Time),
+			Balance:  number(point.Balance),
+			Equity:   number(point.Equity),
+			Drawdown: number(drawdown),
+		})
+	}
+	return records
+}
+
+func (stats *Statistics) summaryRecord() summaryRecord {
+	costs := stats.Costs()
+	return summaryRecord{
+		InitialBalance:  number(stats.initialBalance),
+		FinalBalance:    number(stats.initialBalance + stats.NetProfit),
+		NetProfit:       number(stats.NetProfit),
+		ROIPercentage:   number(stats.ROIPercentage),
+		SharpeRatio:     number(stats.SharpeRatio),
+		WinRate:         number(stats.WinRate),
+		MaxDrawdown:     number(stats.MaxDrawdown),
+		TotalTrades:     stats.TotalTrades,
+		TotalDataPoints: stats.TotalDataPoints,
+		Liquidations:    stats.Liquidations,
+		LiquidationLoss: number(stats.LiquidationLoss),
+		MakerFees:       number(costs.MakerFees),
+		TakerFees:       number(costs.TakerFees),
+		LiquidationFees: number(costs.LiquidationFees),
+		SlippageCost:    number(costs.Slippage),
+		FundingPaid:     number(costs.Funding),
+		TotalCosts:      number(costs.Total),
+	}
+}
+
+//row lists the values of the summary in the order of the summary columns
+func (summary summaryRecord) row() []string {
+	return []string{ftoa(summary.InitialBalance), ftoa(summary.FinalBalance), ftoa(summary.NetProfit),
+		ftoa(summary.ROIPercentage), ftoa(summary.SharpeRatio), ftoa(summary.WinRate), ftoa(summary.MaxDrawdown),
+		itoa(summary.TotalTrades), itoa(summary.TotalDataPoints), itoa(summary.Liquidations),
+		ftoa(summary.LiquidationLoss), ftoa(summary.MakerFees), ftoa(summary.TakerFees), ftoa(summary.LiquidationFees),
+		ftoa(summary.SlippageCost), ftoa(summary.FundingPaid), ftoa(summary.TotalCosts)}
+}
+
+func (stats *Statistics) periodRecords() []periodRecord {
+	groups := []struct {
+		period  string
+		returns []PeriodReturn
+	}{
+		{"day", stats.DailyReturns()},
+		{"week", stats.WeeklyReturns()},
+		{"month", stats.MonthlyReturns()},
+		{"year", stats.YearlyReturns()},
+	}
+
+	records := []periodRecord{}
+	for _, group := range groups {
+		for _, period := range group.returns {
+			records = append(records, periodRecord{
+				Period:      group.period,
+				Start:       formatTime(period.Start),
+				Return:      number(period.Return),
+				NetProfit:   number(period.NetProfit),
+				Trades:      period.Trades,
+				WinRate:     number(period.WinRate),
+				MaxDrawdown: number(period.MaxDrawdown),
+			})
+		}
+	}
+	return records
+}
+
+func (stats *Statistics) sessionRecords() []sessionRecord {
+	groups := []struct {
+		breakdown string
+		sessions  []SessionPerformance
+	}{
+		{"weekday", stats.DayOfWeekPerformance()},
+		{"hour", stats.HourOfDayPerformance()},
+	}
+
+	records := []sessionRecord{}
+	for _, group := range groups {
+		for _, session := range group.sessions {
+			records = append(records, sessionRecord{
+				Breakdown:     group.breakdown,
+				Session:       session.Session,
+				Trades:        session.Trades,
+				WinRate:       number(session.WinRate),
+				NetProfit:     number(session.NetProfit),
+				AverageReturn: number(session.AverageReturn),
+			})
+		}
+	}
+	return records
+}
+
+//writeCSV creates a csv file with a header followed by the provided rows
+func writeCSV(path string, columns []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+//writeJSON creates a json file with the indented encoding of the value
+func writeJSON(path string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+//formatTime formats a timestamp as RFC3339, a zero time is exported as a empty value
+func formatTime(timestamp time.Time) string {
+	if timestamp.IsZero() {
+		return ""
+	}
+	return timestamp.UTC().Format(time.RFC3339)
+}
+
+func ftoa(value number) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 64)
+}
+
+func itoa(value int) string {
+	return strconv.Itoa(value)
+}
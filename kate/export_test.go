@@ -0,0 +1,118 @@
+package kate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExportCSV(t *testing.T) {
+	stats := runSimpleStrategy(t, "../testdata/ETHUSD1.csv")
+	dir, err := ioutil.TempDir("", "kate-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := stats.ExportCSV(dir); err != nil {
+		t.Fatal("could`t export the run." + err.Error())
+	}
+
+	var tests = []struct {
+		fileName     string
+		columns      []string
+		expectedRows int
+	}{
+		{"trades.csv", tradeColumns, stats.TotalTrades},
+		{"orders.csv", orderColumns, len(stats.Orders())},
+		{"equity.csv", equityColumns, len(stats.EquityCurve())},
+		{"summary.csv", summaryColumns, 1},
+		{"returns.csv", periodColumns, len(stats.DailyReturns()) + len(stats.WeeklyReturns()) +
+			len(stats.MonthlyReturns()) + len(stats.YearlyReturns())},
+		{"sessions.csv", sessionColumns, 7 + 24},
+		{"interventions.csv", interventionColumns, 0},
+	}
+
+	for _, test := range tests {
+		rows := readCSV(t, filepath.Join(dir, test.fileName))
+		if !reflect.DeepEqual(rows[0], test.columns) {
+			t.Errorf("The header of %v is %v the expected was %v", test.fileName, rows[0], test.columns)
+		}
+		if len(rows)-1 != test.expectedRows {
+			t.Errorf("The file %v has %v rows the expected amount is %v", test.fileName, len(rows)-1, test.expectedRows)
+		}
+	}
+
+	trades := readCSV(t, filepath.Join(dir, "trades.csv"))
+	if trades[1][2] != "2021-04-11T21:45:00Z" || trades[1][4] != "LONG" {
+		t.Errorf("The first exported trade contains wrong values: %v", trades[1])
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	stats := runSimpleStrategy(t, "../testdata/mockdata.csv")
+	dir, err := ioutil.TempDir("", "kate-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := stats.ExportJSON(dir); err != nil {
+		t.Fatal("could`t export the run." + err.Error())
+	}
+
+	var trades []map[string]interface{}
+	readJSON(t, filepath.Join(dir, "trades.json"), &trades)
+	if len(trades) != stats.TotalTrades {
+		t.Errorf("The amount of exported trades is %v the expected amount is %v", len(trades), stats.TotalTrades)
+	}
+	for _, column := range tradeColumns {
+		if _, ok := trades[0][column]; !ok {
+			t.Errorf("The exported trade is missing the field %v", column)
+		}
+	}
+
+	var summary map[string]interface{}
+	readJSON(t, filepath.Join(dir, "summary.json"), &summary)
+	if summary["net_profit"] != stats.NetProfit || summary["total_trades"] != float64(stats.TotalTrades) {
+		t.Errorf("The exported summary %v does not match the run statistics %+v", summary, stats)
+	}
+}
+
+func runSimpleStrategy(t *testing.T, filePath string) *Statistics {
+	data, err := PricesFromCSV(filePath)
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	backtester := NewBacktester(newSimpleStrategy(), data)
+	backtester.SetFixedTradeAmount(10)
+	return backtester.Run()
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func readJSON(t *testing.T, path string, value interface{}) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, value); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,202 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+)
+
+//GeneticSearch optimizes the parameters of a strategy evolving a population of parameter sets,
+//it explores big parameter spaces evaluating only a fraction of the combinations of a grid search
+type GeneticSearch struct {
+	Factory        StrategyFactory
+	Space          []Parameter
+	Data           *DataHandler //price data shared by every run, it is only read during the search
+	Objective      Objective    //fitness of each parameter set, higher is better
+	Setup          func(backtester *Backtester)
+	Workers        int //amount of runs executed in parallel
+	PopulationSize int
+	Generations    int     //maximum amount of generations
+	CrossoverRate  float64 //probability of a child mixing the parameters of two parents instead of copying one
+	MutationRate   float64 //probability of each parameter of a child being replaced by a random value
+	Elitism        int     //amount of the best individuals copied unchanged to the next generation
+	TournamentSize int     //amount of individuals competing to be selected as parent
+	Patience       int     //generations without improvement before stopping early, zero disables early stopping
+	Seed           int64   //seed of the random generator, the same seed always produces the same result
+}
+
+//EvolutionResult contains every parameter set evaluated by a genetic search
+type EvolutionResult struct {
+	Results    OptimizationResults //distinct parameter sets evaluated ranked by the objective
+	BestScores []float64           //best score found at the end of each generation
+}
+
+//individual is a parameter set represented by the index of the value chosen for each parameter
+type individual []int
+
+//NewGeneticSearch creates a genetic search with a population of 40 individuals evolved for up to 30 generations
+func NewGeneticSearch(factory StrategyFactory, data *DataHandler, objective Objective, space ...Parameter) *GeneticSearch {
+	return &GeneticSearch{
+		Factory:        factory,
+		Space:          space,
+		Data:           data,
+		Objective:      objective,
+		Workers:        runtime.NumCPU(),
+		PopulationSize: 40,
+		Generations:    30,
+		CrossoverRate:  0.8,
+		MutationRate:   0.1,
+		Elitism:        2,
+		TournamentSize: 3,
+		Patience:       5,
+	}
+}
+
+//Run evolves the population until the maximum amount of generations or the early stopping is reached
+func (search *GeneticSearch) Run() (*EvolutionResult, error) {
+	if search.Factory == nil || search.Data == nil || search.Objective == nil {
+		return nil, fmt.Errorf("the genetic search requires a strategy factory, price data and a objective")
+	}
+	if search.PopulationSize < 2 || search.Generations < 1 {
+		return nil, fmt.Errorf("the genetic search requires a population of at least 2 individuals and 1 generation")
+	}
+	for _, parameter := range search.Space {
+		if len(parameter.Values) == 0 {
+			return nil, fmt.Errorf("the parameter '%v' has no values to search", parameter.Name)
+		}
+	}
+
+	random := rand.New(rand.NewSource(search.Seed))
+	evaluator := &evaluator{factory: search.Factory, data: search.Data, objective: search.Objective,
+		setup: search.Setup, workers: search.Workers}
+	evaluated := map[string]OptimizationResult{}
+
+	population := make([]individual, search.PopulationSize)
+	for i := range population {
+		population[i] = search.randomIndividual(random)
+	}
+
+	result := &EvolutionResult{}
+	best, stale := math.Inf(-1), 0
+	for generation := 0; generation < search.Generations; generation++ {
+		search.evaluate(evaluator, population, evaluated)
+		sort.SliceStable(population, func(i, j int) bool {
+			return isFitter(evaluated[search.key(population[i])].Score, evaluated[search.key(population[j])].Score)
+		})
+
+		generationBest := evaluated[search.key(population[0])].Score
+		result.BestScores = append(result.BestScores, generationBest)
+		if generationBest > best {
+			best, stale = generationBest, 0
+		} else if stale++; search.Patience > 0 && stale >= search.Patience {
+			break
+		}
+
+		if generation < search.Generations-1 {
+			population = search.nextGeneration(random, population, evaluated)
+		}
+	}
+
+	for _, evaluation := range evaluated {
+		result.Results = append(result.Results, evaluation)
+	}
+	sort.Slice(result.Results, func(i, j int) bool {
+		return result.Results[i].Params.String() < result.Results[j].Params.String()
+	})
+	result.Results.rank()
+	return result, nil
+}
+
+//evaluate runs the backtests of the individuals that were not evaluated yet
+func (search *GeneticSearch) evaluate(evaluator *evaluator, population []individual, evaluated map[string]OptimizationResult) {
+	var pending []Params
+	queued := map[string]bool{}
+	for _, genes := range population {
+		key := search.key(genes)
+		if _, ok := evaluated[key]; !ok && !queued[key] {
+			queued[key] = true
+			pending = append(pending, search.params(genes))
+		}
+	}
+
+	for _, evaluation := range evaluator.evaluate(pending) {
+		evaluated[evaluation.Params.String()] = evaluation
+	}
+}
+
+//nextGeneration keeps the elite and fills the population with children of parents selected by tournaments
+func (search *GeneticSearch) nextGeneration(random *rand.Rand, population []individual,
+	evaluated map[string]OptimizationResult) []individual {
+	next := make([]individual, 0, len(population))
+	for i := 0; i < search.Elitism && i < len(population); i++ {
+		next = append(next, population[i])
+	}
+
+	for len(next) < len(population) {
+		first := search.tournament(random, population, evaluated)
+		child := append(individual{}, first...)
+		if random.Float64() < search.CrossoverRate {
+			second := search.tournament(random, population, evaluated)
+			for gene := range child {
+				if random.Intn(2) == 1 {
+					child[gene] = second[gene]
+				}
+			}
+		}
+		for gene := range child {
+			if random.Float64() < search.MutationRate {
+				child[gene] = random.Intn(len(search.Space[gene].Values))
+			}
+		}
+		next = append(next, child)
+	}
+	return next
+}
+
+//tournament selects the fittest of a random group of individuals
+func (search *GeneticSearch) tournament(random *rand.Rand, population []individual,
+	evaluated map[string]OptimizationResult) individual {
+	size := search.TournamentSize
+	if size < 1 {
+		size = 1
+	}
+	winner := population[random.Intn(len(population))]
+	for i := 1; i < size; i++ {
+		challenger := population[random.Intn(len(population))]
+		if isFitter(evaluated[search.key(challenger)].Score, evaluated[search.key(winner)].Score) {
+			winner = challenger
+		}
+	}
+	return winner
+}
+
+func (search *GeneticSearch) randomIndividual(random *rand.Rand) individual {
+	genes := make(individual, len(search.Space))
+	for i, parameter := range search.Space {
+		genes[i] = random.Intn(len(parameter.Values))
+	}
+	return genes
+}
+
+//params converts the genes of a individual to the parameters used by the strategy factory
+func (search *GeneticSearch) params(genes individual) Params {
+	params := make(Params, len(genes))
+	for i, gene := range genes {
+		params[search.Space[i].Name] = search.Space[i].Values[gene]
+	}
+	return params
+}
+
+func (search *GeneticSearch) key(genes individual) string {
+	return search.params(genes).String()
+}
+
+//isFitter checks if the first score is better than the second, NaN scores are always the worst
+func isFitter(first, second float64) bool {
+	if math.IsNaN(second) {
+		return !math.IsNaN(first)
+	}
+	return first > second
+}
@@ -0,0 +1,63 @@
+package kate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeneticSearch(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	objective, _ := MetricObjective("Net profit")
+	space := []Parameter{IntRange("leverage", 5, 30, 5), FloatRange("target", 0.1, 2, 0.1)}
+	setup := func(backtester *Backtester) {
+		backtester.SetBalance(1000)
+		backtester.SetFixedTradeAmount(20)
+	}
+
+	grid := NewGridSearch(newTargetStrategy, data, objective, space...)
+	grid.Setup = setup
+	gridResults, err := grid.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search := NewGeneticSearch(newTargetStrategy, data, objective, space...)
+	search.Setup = setup
+	search.PopulationSize = 16
+	search.Generations = 12
+	search.Seed = 3
+
+	first, err := search.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := search.Run()
+	if !reflect.DeepEqual(first.BestScores, second.BestScores) || first.Results.Best().Params.String() != second.Results.Best().Params.String() {
+		t.Errorf("Searches with the same seed should produce the same result")
+	}
+
+	if len(first.Results) >= len(gridResults) {
+		t.Errorf("The genetic search evaluated %v of the %v combinations", len(first.Results), len(gridResults))
+	}
+
+	for i := 1; i < len(first.BestScores); i++ {
+		if first.BestScores[i] < first.BestScores[i-1] {
+			t.Errorf("The elitism should never lose the best individual: %v", first.BestScores)
+		}
+	}
+
+	best := first.Results.Best()
+	if best.Score < gridResults[4].Score {
+		t.Errorf("The genetic search found %v (%v) that is not in the top 5 of the grid search, the best is %v (%v)",
+			best.Params, best.Score, gridResults[0].Params, gridResults[0].Score)
+	}
+
+	search.Patience = 1
+	search.Generations = 50
+	if stopped, _ := search.Run(); len(stopped.BestScores) >= 50 {
+		t.Errorf("The search should stop early when the best score doesn't improve")
+	}
+}
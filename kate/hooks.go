@@ -0,0 +1,118 @@
+package kate
+
+import "time"
+
+//RunConfig is the configuration of a run delivered to strategies implementing StartListener
+type RunConfig struct {
+	InitialBalance           float64
+	MakerFeePercentage       float64
+	TakerFeePercentage       float64
+	SlippagePercentage       float64
+	AmountPerTradePercentage float64
+	FixedTradeAmount         float64
+	Lookback                 int
+	DataPoints               int
+	Timeframes               []string
+}
+
+//StartListener is implemented by strategies that need to be set up at the start of every run
+type StartListener interface {
+	OnStart(config RunConfig)
+}
+
+//EndListener is implemented by strategies that need the results at the end of every run
+type EndListener interface {
+	OnEnd(stats *Statistics)
+}
+
+//OrderFilledListener is implemented by strategies notified when a order is filled, including the execution
+//of stoploss and takeprofit orders
+type OrderFilledListener interface {
+	OnOrderFilled(order Order)
+}
+
+//PositionClosedListener is implemented by strategies notified when a position is closed
+type PositionClosedListener interface {
+	OnPositionClosed(position Position, reason ExitReason)
+}
+
+//LiquidationListener is implemented by strategies notified when a position is liquidated
+type LiquidationListener interface {
+	OnLiquidation(position Position)
+}
+
+//DayChangeListener is implemented by strategies notified on the first candle of each UTC day,
+//it requires price data with timestamps
+type DayChangeListener interface {
+	OnDayChange(day time.Time)
+}
+
+//listener is the value checked for the optional hooks, the StrategyV2 itself when it was adapted
+func (sim *simulation) listener() interface{} {
+	return listenerOf(sim.myStrategy)
+}
+
+//listenerOf is the value of the strategy checked for the optional hooks
+func listenerOf(strategy Strategy) interface{} {
+	if adapter, ok := strategy.(*strategyV2Adapter); ok {
+		return adapter.strategy
+	}
+	return strategy
+}
+
+//config describes the configuration of the run
+func (sim *simulation) config() RunConfig {
+	handler := sim.exchangeHandler
+	config := RunConfig{
+		InitialBalance:           handler.balance,
+		MakerFeePercentage:       100 * handler.makerFee,
+		TakerFeePercentage:       100 * handler.takerFee,
+		SlippagePercentage:       100 * handler.slippage,
+		AmountPerTradePercentage: 100 * handler.amountPerTrade,
+		FixedTradeAmount:         handler.fixedTradeAmount,
+		Lookback:                 sim.lookback,
+		DataPoints:               len(sim.dataHandler.Prices),
+	}
+	for _, timeframe := range sim.timeframes {
+		config.Timeframes = append(config.Timeframes, timeframe.name)
+	}
+	return config
+}
+
+//registerHooks connects the exchange notifications to the hooks implemented by the strategy
+func (sim *simulation) registerHooks() {
+	listener := sim.listener()
+	if filled, ok := listener.(OrderFilledListener); ok {
+		sim.exchangeHandler.filled = filled.OnOrderFilled
+	}
+
+	closed, notifyClose := listener.(PositionClosedListener)
+	liquidated, notifyLiquidation := listener.(LiquidationListener)
+	if notifyClose || notifyLiquidation {
+		sim.exchangeHandler.closed = func(position Position) {
+			if notifyLiquidation && position.ExitReason == LiquidationExit {
+				liquidated.OnLiquidation(position)
+			}
+			if notifyClose {
+				closed.OnPositionClosed(position, position.ExitReason)
+			}
+		}
+	}
+}
+
+//checkDayChange resets the daily risk limits and notifies the strategy when the candle is the first of a new UTC day
+func (sim *simulation) checkDayChange(previous, current time.Time) {
+	if previous.IsZero() || current.IsZero() {
+		return
+	}
+	previousYear, previousMonth, previousDay := previous.UTC().Date()
+	year, month, day := current.UTC().Date()
+	if year == previousYear && month == previousMonth && day == previousDay {
+		return
+	}
+
+	sim.risk.startDay(sim.exchangeHandler.equity())
+	if listener, ok := sim.listener().(DayChangeListener); ok {
+		listener.OnDayChange(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+	}
+}
@@ -0,0 +1,111 @@
+package kate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+//hooksStrategy runs the simpleStrategy recording every lifecycle notification
+type hooksStrategy struct {
+	simpleStrategy
+	config       RunConfig
+	starts       int
+	end          *Statistics
+	fills        []Order
+	closed       []Position
+	liquidations int
+	days         []time.Time
+}
+
+func (strategy *hooksStrategy) OnStart(config RunConfig) {
+	strategy.starts++
+	strategy.config = config
+}
+
+func (strategy *hooksStrategy) OnEnd(stats *Statistics) {
+	strategy.end = stats
+}
+
+func (strategy *hooksStrategy) OnOrderFilled(order Order) {
+	strategy.fills = append(strategy.fills, order)
+}
+
+func (strategy *hooksStrategy) OnPositionClosed(position Position, reason ExitReason) {
+	if position.ExitReason != reason {
+		panic("the reason should match the exit reason of the position")
+	}
+	strategy.closed = append(strategy.closed, position)
+}
+
+func (strategy *hooksStrategy) OnLiquidation(position Position) {
+	strategy.liquidations++
+}
+
+func (strategy *hooksStrategy) OnDayChange(day time.Time) {
+	strategy.days = append(strategy.days, day)
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy := &hooksStrategy{}
+	backtester := NewBacktester(strategy, data)
+	backtester.SetBalance(1000)
+	backtester.SetFixedTradeAmount(10)
+	stats := backtester.Run()
+
+	if strategy.starts != 1 || strategy.config.InitialBalance != 1000 || strategy.config.FixedTradeAmount != 10 ||
+		strategy.config.DataPoints != len(data.Prices) || !isEqual(strategy.config.TakerFeePercentage, 0.04) {
+		t.Errorf("Unexpected start notification %+v", strategy.config)
+	}
+	if strategy.end != stats {
+		t.Errorf("The end notification should receive the statistics of the run")
+	}
+	if len(strategy.closed) != stats.TotalTrades || strategy.liquidations != stats.Liquidations || stats.Liquidations != 1 {
+		t.Errorf("Expected %v closed positions and %v liquidations, found %v and %v", stats.TotalTrades,
+			stats.Liquidations, len(strategy.closed), strategy.liquidations)
+	}
+
+	opens, exits := 0, 0
+	for _, order := range strategy.fills {
+		if order.Status != Filled {
+			t.Fatalf("Only filled orders should be notified, found %v", order.Status)
+		}
+		if order.Action == OpenAction {
+			opens++
+		} else {
+			exits++
+		}
+	}
+	if opens < stats.TotalTrades || opens > stats.TotalTrades+1 || exits != stats.TotalTrades-stats.Liquidations {
+		t.Errorf("Unexpected fills: %v opens and %v exits for %v trades", opens, exits, stats.TotalTrades)
+	}
+
+	var filled []Order
+	executions := 0
+	for _, order := range stats.Orders() {
+		if order.Status != Filled {
+			continue
+		}
+		filled = append(filled, order)
+		if order.Action == StoplossAction || order.Action == TakeProfitAction {
+			executions++
+		}
+	}
+	if executions == 0 || !reflect.DeepEqual(filled, strategy.fills) {
+		t.Errorf("The notified fills should be the filled orders of the run including %v stoploss/takeprofit "+
+			"executions, found %+v", executions, strategy.fills)
+	}
+
+	days := map[string]bool{}
+	for _, candle := range data.Prices[:len(data.Prices)-1] {
+		days[candle.Time().UTC().Format("2006-01-02")] = true
+	}
+	if len(strategy.days) != len(days)-1 || strategy.days[0].Hour() != 0 {
+		t.Errorf("Expected %v day changes, found %v", len(days)-1, len(strategy.days))
+	}
+}
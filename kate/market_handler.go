@@ -8,6 +8,8 @@ type MarketHandler interface {
 	marketFee(position *Position) float64
 	limitFee(position *Position) float64
 	liquidationFee(position *Position) float64
+	funding(position *Position, price, rate float64) float64
+	notional(size, price float64) float64
 }
 
 func newMarketHandler(market MarketType, makerFee, takerFee float64) MarketHandler {
@@ -0,0 +1,162 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+//MonteCarlo evaluates how dependent the result of a run is on the order and the occurrence of its trades
+//by simulating random sequences of the trades. The simulations use the absolute pnl of each trade.
+type MonteCarlo struct {
+	Simulations        int
+	Seed               int64   //seed of the random generator, the same seed always produces the same result
+	Resample           bool    //when true trades are drawn with replacement (bootstrap) instead of reshuffled
+	SkipProbability    float64 //probability (0.1 = 10%) of each trade being skipped
+	MaxSlippagePercent float64 //each trade pays a random slippage up to this percentage of the position value (in the currency of the balance)
+	RuinThreshold      float64 //fraction of the initial balance that denotes ruin when the equity reaches it
+}
+
+//Distribution is the sorted set of values produced by the simulations
+type Distribution struct {
+	Values []float64
+	Mean   float64
+	StdDev float64
+}
+
+//MonteCarloResult contains the distributions produced by the simulations
+type MonteCarloResult struct {
+	InitialBalance  float64
+	FinalEquity     Distribution
+	MaxDrawdown     Distribution //fractions of the peak equity
+	RuinProbability float64      //fraction of the simulations that reached the ruin threshold
+}
+
+//NewMonteCarlo creates a simulation that reshuffles the trades, ruin is defined as losing half of the balance
+func NewMonteCarlo(simulations int, seed int64) *MonteCarlo {
+	return &MonteCarlo{Simulations: simulations, Seed: seed, RuinThreshold: 0.5}
+}
+
+//Run simulates random sequences of the trades from the run
+func (mc *MonteCarlo) Run(stats *Statistics) (*MonteCarloResult, error) {
+	if mc.Simulations <= 0 {
+		return nil, fmt.Errorf("the amount of simulations must be positive")
+	}
+	if len(stats.trades) == 0 {
+		return nil, fmt.Errorf("the run has no trades to simulate")
+	}
+
+	random := rand.New(rand.NewSource(mc.Seed))
+	initial := stats.initialBalance
+	finals := make([]float64, mc.Simulations)
+	drawdowns := make([]float64, mc.Simulations)
+	ruined := 0
+
+	sequence := make([]int, len(stats.trades))
+	for simulation := 0; simulation < mc.Simulations; simulation++ {
+		mc.drawSequence(random, sequence)
+
+		equity, peak, drawdown, ruin := initial, initial, 0.0, false
+		for _, index := range sequence {
+			if mc.SkipProbability > 0 && random.Float64() < mc.SkipProbability {
+				continue
+			}
+			trade := stats.trades[index]
+			equity += trade.RealizedPNL
+			if mc.MaxSlippagePercent > 0 {
+				equity -= stats.notional(trade.Size, trade.EntryPrice) * random.Float64() * mc.MaxSlippagePercent / 100
+			}
+
+			peak = math.Max(peak, equity)
+			if peak > 0 {
+				drawdown = math.Max(drawdown, (peak-equity)/peak)
+			}
+			if equity <= initial*mc.RuinThreshold {
+				ruin = true
+			}
+		}
+
+		finals[simulation], drawdowns[simulation] = equity, drawdown
+		if ruin {
+			ruined++
+		}
+	}
+
+	return &MonteCarloResult{
+		InitialBalance:  initial,
+		FinalEquity:     newDistribution(finals),
+		MaxDrawdown:     newDistribution(drawdowns),
+		RuinProbability: float64(ruined) / float64(mc.Simulations),
+	}, nil
+}
+
+//drawSequence fills the sequence with the indexes of the trades used by a simulation
+func (mc *MonteCarlo) drawSequence(random *rand.Rand, sequence []int) {
+	if mc.Resample {
+		for i := range sequence {
+			sequence[i] = random.Intn(len(sequence))
+		}
+		return
+	}
+	for i := range sequence {
+		sequence[i] = i
+	}
+	random.Shuffle(len(sequence), func(i, j int) {
+		sequence[i], sequence[j] = sequence[j], sequence[i]
+	})
+}
+
+func newDistribution(values []float64) Distribution {
+	sort.Float64s(values)
+	return Distribution{Values: values, Mean: average(values), StdDev: sampleStdDev(values)}
+}
+
+//Percentile returns the value below which the percentage (0-100) of the simulations are found
+func (distribution Distribution) Percentile(percentage float64) float64 {
+	if len(distribution.Values) == 0 {
+		return math.NaN()
+	}
+	position := percentage / 100 * float64(len(distribution.Values)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	if lower < 0 {
+		return distribution.Values[0]
+	}
+	if upper >= len(distribution.Values) {
+		return distribution.Values[len(distribution.Values)-1]
+	}
+	weight := position - float64(lower)
+	return distribution.Values[lower]*(1-weight) + distribution.Values[upper]*weight
+}
+
+//ConfidenceInterval returns the range containing the level (0.95 = 95%) of the simulations around the median
+func (distribution Distribution) ConfidenceInterval(level float64) (float64, float64) {
+	tail := (1 - level) / 2 * 100
+	return distribution.Percentile(tail), distribution.Percentile(100 - tail)
+}
+
+//String renders the percentiles of the final equity and drawdown distributions and the probability of ruin
+func (result *MonteCarloResult) String() string {
+	rows := [][]string{{"Distribution", "Mean", "5%", "25%", "50%", "75%", "95%"}}
+	for _, row := range []struct {
+		name         string
+		distribution Distribution
+		scale        float64
+	}{
+		{"Final equity", result.FinalEquity, 1},
+		{"Max drawdown %", result.MaxDrawdown, 100},
+	} {
+		cells := []string{row.name, fmt.Sprintf("%.2f", row.scale*row.distribution.Mean)}
+		for _, percentile := range []float64{5, 25, 50, 75, 95} {
+			cells = append(cells, fmt.Sprintf("%.2f", row.scale*row.distribution.Percentile(percentile)))
+		}
+		rows = append(rows, cells)
+	}
+
+	var text strings.Builder
+	text.WriteString(renderTable(rows, nil))
+	fmt.Fprintf(&text, "\nRuin probability: %.2f%%\n", 100*result.RuinProbability)
+	return text.String()
+}
@@ -0,0 +1,99 @@
+package kate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMonteCarloReshuffle(t *testing.T) {
+	stats := runSimpleStrategy(t, "../testdata/ETHUSD3.csv")
+	result, err := NewMonteCarlo(200, 42).Run(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Reshuffling never changes the final equity, only the path and the drawdown
+	final := stats.initialBalance + stats.NetProfit
+	if !isEqual(result.FinalEquity.Values[0], final) || !isEqual(result.FinalEquity.Values[199], final) {
+		t.Errorf("The final equity of reshuffled trades should always be %v, found %v to %v", final,
+			result.FinalEquity.Values[0], result.FinalEquity.Values[199])
+	}
+
+	if result.MaxDrawdown.Values[0] == result.MaxDrawdown.Values[199] {
+		t.Errorf("The drawdown should change with the order of the trades")
+	}
+
+	if table := result.String(); !strings.Contains(table, "Ruin probability") {
+		t.Errorf("The result table is missing the ruin probability:\n%v", table)
+	}
+}
+
+func TestMonteCarloOptions(t *testing.T) {
+	stats := runSimpleStrategy(t, "../testdata/ETHUSD3.csv")
+	final := stats.initialBalance + stats.NetProfit
+
+	resample := NewMonteCarlo(300, 7)
+	resample.Resample = true
+	first, _ := resample.Run(stats)
+	second, _ := resample.Run(stats)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Simulations with the same seed should produce the same result")
+	}
+	low, high := first.FinalEquity.ConfidenceInterval(0.9)
+	if low >= high || low > first.FinalEquity.Percentile(50) || high < first.FinalEquity.Percentile(50) {
+		t.Errorf("The confidence interval %v to %v should contain the median %v", low, high, first.FinalEquity.Percentile(50))
+	}
+
+	skipAll := NewMonteCarlo(10, 1)
+	skipAll.SkipProbability = 1
+	if result, _ := skipAll.Run(stats); result.FinalEquity.Mean != stats.initialBalance || result.RuinProbability != 0 {
+		t.Errorf("Skipping every trade should keep the initial balance, found %v", result.FinalEquity.Mean)
+	}
+
+	slippage := NewMonteCarlo(50, 1)
+	slippage.MaxSlippagePercent = 0.05
+	if result, _ := slippage.Run(stats); result.FinalEquity.Values[49] >= final {
+		t.Errorf("The random slippage should reduce the final equity below %v, found %v", final, result.FinalEquity.Values[49])
+	}
+
+	ruin := NewMonteCarlo(50, 1)
+	ruin.RuinThreshold = 1
+	if result, _ := ruin.Run(stats); result.RuinProbability != 1 {
+		t.Errorf("A losing strategy should always reach a ruin threshold of the initial balance, found %v", result.RuinProbability)
+	}
+}
+
+func TestDistributionPercentile(t *testing.T) {
+	distribution := newDistribution([]float64{5, 1, 4, 2, 3})
+	var tests = []struct {
+		percentile, expected float64
+	}{
+		{0, 1}, {25, 2}, {50, 3}, {62.5, 3.5}, {100, 5},
+	}
+	for _, test := range tests {
+		if value := distribution.Percentile(test.percentile); !isEqual(value, test.expected) {
+			t.Errorf("The percentile %v is %v the expected was %v", test.percentile, value, test.expected)
+		}
+	}
+}
+
+func TestMonteCarloSlippageMarket(t *testing.T) {
+	trades := []Position{{Size: 2, EntryPrice: 100}}
+	var losses []float64
+	for _, market := range []MarketHandler{&USDMarket{}, &CoinMarket{}} {
+		stats := statisticsFromTrades(1000, trades)
+		stats.market = market
+		mc := NewMonteCarlo(1, 1)
+		mc.MaxSlippagePercent = 1
+		result, err := mc.Run(stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		losses = append(losses, 1000-result.FinalEquity.Mean)
+	}
+	//the same random slippage is applied to 200 USD on the USD market and to 0.02 COIN on the COIN market
+	if losses[0] <= 0 || !isEqual(losses[0]/losses[1], 100*100) {
+		t.Errorf("The slippage should be applied to the notional in the currency of the balance, found %v", losses)
+	}
+}
@@ -0,0 +1,192 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+//GridSearch runs a backtest for every combination of the parameters in the space and ranks the results
+type GridSearch struct {
+	Factory   StrategyFactory
+	Space     []Parameter
+	Data      *DataHandler //price data shared by every run, it is only read during the search
+	Objective Objective
+	Setup     func(backtester *Backtester) //optional configuration (balance, fees...) applied before each run
+	Workers   int                          //amount of runs executed in parallel
+}
+
+//OptimizationResult is the outcome of running a strategy with a set of parameters
+type OptimizationResult struct {
+	Params Params
+	Stats  *Statistics
+	Score  float64
+	Err    error //reason the run failed, the stats are nil and the score is NaN when it is set
+}
+
+//OptimizationResults are the results of a optimization ranked from the best to the worst score
+type OptimizationResults []OptimizationResult
+
+//NewGridSearch creates a grid search that runs in parallel on all available CPUs
+func NewGridSearch(factory StrategyFactory, data *DataHandler, objective Objective, space ...Parameter) *GridSearch {
+	return &GridSearch{
+		Factory:   factory,
+		Space:     space,
+		Data:      data,
+		Objective: objective,
+		Workers:   runtime.NumCPU(),
+	}
+}
+
+//Run executes the backtests of all combinations and returns the results ranked by the objective
+func (search *GridSearch) Run() (OptimizationResults, error) {
+	if search.Factory == nil || search.Data == nil || search.Objective == nil {
+		return nil, fmt.Errorf("the grid search requires a strategy factory, price data and a objective")
+	}
+	for _, parameter := range search.Space {
+		if len(parameter.Values) == 0 {
+			return nil, fmt.Errorf("the parameter '%v' has no values to search", parameter.Name)
+		}
+	}
+
+	candidates := make([]Params, combinations(search.Space))
+	for i := range candidates {
+		candidates[i] = paramsAt(search.Space, i)
+	}
+
+	evaluator := &evaluator{factory: search.Factory, data: search.Data, objective: search.Objective,
+		setup: search.Setup, workers: search.Workers}
+	results := OptimizationResults(evaluator.evaluate(candidates))
+	results.rank()
+	return results, nil
+}
+
+//Best returns the result with the highest score, the zero value is returned when there are no results
+func (results OptimizationResults) Best() OptimizationResult {
+	if len(results) == 0 {
+		return OptimizationResult{}
+	}
+	return results[0]
+}
+
+//ExportCSV writes a csv file with a row for each result containing the rank, parameters, score and summary metrics,
+//the metrics of the failed runs are empty
+func (results OptimizationResults) ExportCSV(path string) error {
+	names := results.paramNames()
+	columns := append(append([]string{"rank"}, names...), "score")
+	columns = append(columns, summaryColumns...)
+
+	rows := make([][]string, 0, len(results))
+	for rank, result := range results {
+		row := []string{strconv.Itoa(rank + 1)}
+		for _, name := range names {
+			row = append(row, result.Params.Text(name))
+		}
+		row = append(row, ftoa(number(result.Score)))
+		if result.Err != nil {
+			row = append(row, make([]string, len(summaryColumns))...)
+		} else {
+			row = append(row, result.Stats.summaryRecord().row()...)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeCSV(path, columns, rows)
+}
+
+//rank sorts the results from the best to the worst score keeping NaN scores last
+func (results OptimizationResults) rank() {
+	sort.SliceStable(results, func(i, j int) bool {
+		return isFitter(results[i].Score, results[j].Score)
+	})
+}
+
+//succeeded returns the results of the runs that didn't fail
+func (results OptimizationResults) succeeded() OptimizationResults {
+	var succeeded OptimizationResults
+	for _, result := range results {
+		if result.Err == nil {
+			succeeded = append(succeeded, result)
+		}
+	}
+	return succeeded
+}
+
+//paramNames lists the names of all parameters found in the results sorted alphabetically
+func (results OptimizationResults) paramNames() []string {
+	unique := map[string]bool{}
+	for _, result := range results {
+		for name := range result.Params {
+			unique[name] = true
+		}
+	}
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//evaluator runs backtests for sets of parameters in parallel sharing the same price data
+type evaluator struct {
+	factory   StrategyFactory
+	data      *DataHandler
+	objective Objective
+	setup     func(backtester *Backtester)
+	workers   int
+}
+
+//evaluate runs a backtest for each set of parameters, the results keep the order of the candidates
+func (eval *evaluator) evaluate(candidates []Params) []OptimizationResult {
+	results := make([]OptimizationResult, len(candidates))
+	workers := eval.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index] = eval.run(candidates[index])
+			}
+		}()
+	}
+
+	for index := range candidates {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+//run executes a single backtest with a new strategy instance created for the parameters,
+//a panic of the strategy is reported as the error of the result
+func (eval *evaluator) run(params Params) (result OptimizationResult) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = OptimizationResult{Params: params, Score: math.NaN(),
+				Err: fmt.Errorf("the run panicked: %v", recovered)}
+		}
+	}()
+
+	backtester := NewBacktester(eval.factory(params), eval.data)
+	if eval.setup != nil {
+		eval.setup(backtester)
+	}
+	stats := backtester.Run()
+	return OptimizationResult{Params: params, Stats: stats, Score: eval.objective(stats)}
+}
@@ -0,0 +1,184 @@
+package kate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//targetStrategy is the simpleStrategy with configurable leverage and stoploss/takeprofit distances
+type targetStrategy struct {
+	simpleStrategy
+	leverage uint
+	target   float64 //percentage distance of the stoploss and takeprofit from the entry price
+}
+
+func newTargetStrategy(params Params) Strategy {
+	return &targetStrategy{leverage: uint(params.Int("leverage")), target: params.Float("target")}
+}
+
+func (strategy *targetStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	if evt := strategy.simpleStrategy.OpenNewPosition(latestPrice); evt != nil {
+		evt.Leverage = strategy.leverage
+		return evt
+	}
+	return nil
+}
+
+func (strategy *targetStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	if openPosition.Stoploss <= 0 {
+		return &StoplossEvt{Price: openPosition.EntryPrice * (1 - strategy.target/100)}
+	}
+	return nil
+}
+
+func (strategy *targetStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	if openPosition.TakeProfit <= 0 {
+		return &TakeProfitEvt{Price: openPosition.EntryPrice * (1 + strategy.target/100)}
+	}
+	return nil
+}
+
+func TestParameterRanges(t *testing.T) {
+	var tests = []struct {
+		parameter      Parameter
+		expectedValues []interface{}
+	}{
+		{IntRange("period", 5, 15, 5), []interface{}{5, 10, 15}},
+		{IntRange("period", 1, 4, 2), []interface{}{1, 3}},
+		{FloatRange("target", 0.1, 0.3, 0.1), []interface{}{0.1, 0.2, 0.30000000000000004}},
+		{Choice("side", "long", "short"), []interface{}{"long", "short"}},
+	}
+
+	for _, test := range tests {
+		if len(test.parameter.Values) != len(test.expectedValues) {
+			t.Errorf("The values of %v are %v the expected were %v", test.parameter.Name, test.parameter.Values, test.expectedValues)
+			continue
+		}
+		for i, value := range test.parameter.Values {
+			if value != test.expectedValues[i] {
+				t.Errorf("The values of %v are %v the expected were %v", test.parameter.Name, test.parameter.Values, test.expectedValues)
+				break
+			}
+		}
+	}
+
+	space := []Parameter{IntRange("a", 1, 2, 1), Choice("b", "x", "y", "z")}
+	if total := combinations(space); total != 6 {
+		t.Errorf("The space should contain 6 combinations but %v were found", total)
+	}
+	if params := paramsAt(space, 4); params.Int("a") != 2 || params.Text("b") != "y" {
+		t.Errorf("The fifth combination of the space is %v the expected was a=2 b=y", params)
+	}
+}
+
+func TestGridSearch(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD1.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	objective, err := MetricObjective("Net profit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search := NewGridSearch(newTargetStrategy, data, objective, IntRange("leverage", 10, 30, 10),
+		FloatRange("target", 0.25, 1, 0.25))
+	search.Setup = func(backtester *Backtester) {
+		backtester.SetBalance(100)
+		backtester.SetFixedTradeAmount(5)
+	}
+
+	results, err := search.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 12 {
+		t.Fatalf("The grid search should run 12 combinations but %v results were found", len(results))
+	}
+
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("The results are not ranked by the objective: %v > %v", results[i].Score, results[i-1].Score)
+		}
+	}
+
+	//The best result must match a sequential run of the same parameters
+	best := results.Best()
+	backtester := NewBacktester(newTargetStrategy(best.Params), data)
+	search.Setup(backtester)
+	if stats := backtester.Run(); stats.NetProfit != best.Stats.NetProfit || best.Score != best.Stats.NetProfit {
+		t.Errorf("The best result %v (%v) does not match a sequential run (%v)", best.Params, best.Stats.NetProfit, stats.NetProfit)
+	}
+
+	dir, err := ioutil.TempDir("", "kate-grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "results.csv")
+	if err := results.ExportCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSV(t, path)
+	if len(rows) != 13 || rows[0][1] != "leverage" || rows[0][2] != "target" || rows[0][3] != "score" {
+		t.Errorf("The exported results contain wrong values: %v", rows[0])
+	}
+}
+
+//panickingStrategy panics on the first candle
+type panickingStrategy struct {
+	simpleStrategy
+}
+
+func (strategy *panickingStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	panic("broken parameters")
+}
+
+func TestGridSearchPanics(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD1.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	objective, _ := MetricObjective("Net profit")
+	factory := func(params Params) Strategy {
+		if params.Int("leverage") == 20 {
+			return &panickingStrategy{}
+		}
+		return newTargetStrategy(params)
+	}
+	search := NewGridSearch(factory, data, objective, IntRange("leverage", 10, 30, 10), FloatRange("target", 1, 1, 1))
+
+	results, err := search.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, found %v", len(results))
+	}
+	failed := results[len(results)-1]
+	if failed.Params.Int("leverage") != 20 || failed.Err == nil || failed.Stats != nil {
+		t.Errorf("The panicking run should be ranked last with its error, found %+v", failed)
+	}
+	for _, result := range results[:2] {
+		if result.Err != nil || result.Stats == nil {
+			t.Errorf("The other runs should succeed, found %+v", result)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "results.csv")
+	if err := results.ExportCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	if rows := readCSV(t, path); len(rows) != 4 || rows[3][1] != "20" || rows[3][4] != "" {
+		t.Errorf("The failed run should be exported without metrics, found %v", rows)
+	}
+
+	walkForward := NewWalkForward(NewGridSearch(func(params Params) Strategy { return &panickingStrategy{} }, nil,
+		objective, IntRange("leverage", 10, 10, 1)), data, 1000, 500)
+	if _, err := walkForward.Run(); err == nil {
+		t.Errorf("A walk-forward whose in-sample runs all fail should return a error")
+	}
+}
@@ -1,5 +1,7 @@
 package kate
 
+import "time"
+
 //OrderType denotes how/when a execution of a position is made on the exchange.
 //To know more check: https://www.binance.com/en/support/articles/360033779452
 type OrderType int
@@ -11,12 +13,78 @@ const (
 	LIMIT
 )
 
+//OrderAction denotes what a order submitted to the exchange is trying to achieve
+type OrderAction int
+
+const (
+	//OpenAction is a order that opens a new position
+	OpenAction OrderAction = iota
+	//StoplossAction is a order that sets the stoploss of the open position
+	StoplossAction
+	//TakeProfitAction is a order that sets the takeprofit of the open position
+	TakeProfitAction
+	//CloseAction is a order that closes the open position
+	CloseAction
+)
+
+//OrderStatus is the outcome of a order submitted to the exchange
+type OrderStatus int
+
+const (
+	//Filled denotes a order that was executed immediately
+	Filled OrderStatus = iota
+	//Placed denotes a order that was accepted and waits for its trigger price
+	Placed
+	//Rejected denotes a order that was refused by the exchange
+	Rejected
+)
+
+//ExitReason denotes how a position was closed
+type ExitReason int
+
+const (
+	//NoExit is the reason of positions that are still open
+	NoExit ExitReason = iota
+	//TakeProfitExit denotes a position closed by its takeprofit
+	TakeProfitExit
+	//StoplossExit denotes a position closed by its stoploss
+	StoplossExit
+	//LiquidationExit denotes a position closed by a liquidation
+	LiquidationExit
+	//MarketExit denotes a position closed by a market order requested by the strategy
+	MarketExit
+	//EndOfDataExit denotes a position closed at the latest price when the run ended, used by the walk-forward windows
+	EndOfDataExit
+)
+
+//Order is a record of a order submitted to the exchange during a backtest run
+type Order struct {
+	Symbol    string //traded pair when the order belongs to a portfolio, empty otherwise
+	Candle    int    //index of the candle when the order was submitted
+	Time      time.Time
+	Action    OrderAction
+	Type      OrderType
+	Direction Direction
+	Leverage  uint
+	Price     float64 //execution price for market orders or trigger price for stoploss/takeprofit orders
+	Status    OrderStatus
+	Error     string //reason given by the exchange when the order is rejected
+}
+
 //OpenPositionEvt is a event to open a simulated position
 type OpenPositionEvt struct {
 	Event
-	Direction Direction
-	Leverage  uint
-	OrderType OrderType
+	Direction  Direction
+	Leverage   uint
+	OrderType  OrderType
+	Quantity   float64 //optional size of the position (including leverage) in the unit of Position.Size, overriding the sizer
+	Stoploss   float64 //optional stoploss set once the position is opened, also used to size the risk of the position
+	TakeProfit float64 //optional takeprofit set once the position is opened
+}
+
+//ClosePositionEvt is a event to close the open position with a market order
+type ClosePositionEvt struct {
+	Event
 }
 
 //StoplossEvt is a event to set a stoploss
@@ -30,3 +98,52 @@ type TakeProfitEvt struct {
 	Event
 	Price float64
 }
+
+//String returns the name of the order type
+func (orderType OrderType) String() string {
+	if orderType == LIMIT {
+		return "LIMIT"
+	}
+	return "MARKET"
+}
+
+//String returns the name of the order action
+func (action OrderAction) String() string {
+	switch action {
+	case StoplossAction:
+		return "STOPLOSS"
+	case TakeProfitAction:
+		return "TAKEPROFIT"
+	case CloseAction:
+		return "CLOSE"
+	}
+	return "OPEN"
+}
+
+//String returns the name of the order status
+func (status OrderStatus) String() string {
+	switch status {
+	case Placed:
+		return "PLACED"
+	case Rejected:
+		return "REJECTED"
+	}
+	return "FILLED"
+}
+
+//String returns the name of the exit reason
+func (reason ExitReason) String() string {
+	switch reason {
+	case TakeProfitExit:
+		return "TAKEPROFIT"
+	case StoplossExit:
+		return "STOPLOSS"
+	case LiquidationExit:
+		return "LIQUIDATION"
+	case MarketExit:
+		return "MARKET"
+	case EndOfDataExit:
+		return "END"
+	}
+	return "OPEN"
+}
@@ -0,0 +1,243 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+)
+
+//eulerMascheroni is the constant used to estimate the expected maximum Sharpe ratio of independent trials
+const eulerMascheroni = 0.5772156649015329
+
+//OverfittingReport contains the diagnostics of how much the best result of a optimization is inflated by selection
+type OverfittingReport struct {
+	Trials              int
+	PBO                 float64   //probability of backtest overfitting estimated with combinatorially symmetric cross-validation
+	Logits              []float64 //logit of the out-of-sample rank of the best in-sample trial for each combination
+	SharpeRatio         float64   //non annualized sharpe ratio of the returns per candle of the best trial
+	Skewness            float64
+	Kurtosis            float64 //non excess kurtosis (3 for normal returns)
+	ProbabilisticSharpe float64 //probability of the true sharpe ratio of the best trial being above zero
+	ExpectedMaxSharpe   float64 //sharpe ratio expected from the best of the trials when none of them has skill
+	DeflatedSharpe      float64 //probability of the true sharpe ratio being above the expected maximum of the trials
+}
+
+//Overfitting calculates the overfitting diagnostics of the successful optimization results using the returns per
+//candle of the equity curves. The candles are split in the provided amount of blocks (even) for the cross-validation.
+func (results OptimizationResults) Overfitting(blocks int) (*OverfittingReport, error) {
+	results = results.succeeded()
+	if len(results) < 2 {
+		return nil, fmt.Errorf("at least 2 trials are required to estimate the overfitting")
+	}
+
+	matrix := ReturnsMatrix(results)
+	pbo, logits, err := ProbabilityOfOverfitting(matrix, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	sharpes := make([]float64, len(results))
+	for trial := range results {
+		sharpes[trial] = sharpeOf(column(matrix, trial))
+	}
+	best := column(matrix, 0)
+
+	report := &OverfittingReport{
+		Trials:              len(results),
+		PBO:                 pbo,
+		Logits:              logits,
+		SharpeRatio:         sharpeOf(best),
+		Skewness:            skewness(best),
+		Kurtosis:            kurtosis(best),
+		ProbabilisticSharpe: ProbabilisticSharpeRatio(best, 0),
+		ExpectedMaxSharpe:   ExpectedMaxSharpe(sharpes),
+	}
+	report.DeflatedSharpe = DeflatedSharpeRatio(best, sharpes)
+	return report, nil
+}
+
+//String renders the diagnostics as a table
+func (report *OverfittingReport) String() string {
+	rows := [][]string{{"Diagnostic", "Value"},
+		{"Trials", fmt.Sprintf("%v", report.Trials)},
+		{"Probability of overfitting", fmt.Sprintf("%.2f%%", 100*report.PBO)},
+		{"Sharpe ratio (per candle)", fmt.Sprintf("%.4f", report.SharpeRatio)},
+		{"Skewness", fmt.Sprintf("%.4f", report.Skewness)},
+		{"Kurtosis", fmt.Sprintf("%.4f", report.Kurtosis)},
+		{"Expected max sharpe", fmt.Sprintf("%.4f", report.ExpectedMaxSharpe)},
+		{"Probabilistic sharpe", fmt.Sprintf("%.2f%%", 100*report.ProbabilisticSharpe)},
+		{"Deflated sharpe", fmt.Sprintf("%.2f%%", 100*report.DeflatedSharpe)},
+	}
+	return renderTable(rows, nil)
+}
+
+//ReturnsMatrix builds a matrix with the equity returns per candle where each row is a candle and each column
+//is one of the results, the failed runs are skipped. All results must come from runs over the same price data.
+func ReturnsMatrix(results OptimizationResults) [][]float64 {
+	results = results.succeeded()
+	rows := math.MaxInt32
+	for _, result := range results {
+		if len(result.Stats.equityCurve) < rows {
+			rows = len(result.Stats.equityCurve)
+		}
+	}
+
+	matrix := make([][]float64, rows)
+	for row := range matrix {
+		matrix[row] = make([]float64, len(results))
+		for trial, result := range results {
+			previous := result.Stats.initialBalance
+			if row > 0 {
+				previous = result.Stats.equityCurve[row-1].Equity
+			}
+			if previous != 0 {
+				matrix[row][trial] = result.Stats.equityCurve[row].Equity/previous - 1
+			}
+		}
+	}
+	return matrix
+}
+
+//ProbabilityOfOverfitting estimates the probability of backtest overfitting with combinatorially symmetric
+//cross-validation. The rows of the returns matrix (candles) are split in blocks and every combination of half of
+//the blocks is used as in-sample to select the best trial (column), which is then ranked out-of-sample.
+//The logits of the relative ranks are returned together with the probability.
+func ProbabilityOfOverfitting(returns [][]float64, blocks int) (float64, []float64, error) {
+	if blocks < 2 || blocks%2 != 0 {
+		return 0, nil, fmt.Errorf("the amount of blocks must be even and at least 2, found %v", blocks)
+	}
+	if len(returns) < blocks || len(returns[0]) < 2 {
+		return 0, nil, fmt.Errorf("the returns matrix needs at least %v rows and 2 columns", blocks)
+	}
+
+	trials := len(returns[0])
+	size := len(returns) / blocks
+	sums := make([][]float64, blocks)
+	squares := make([][]float64, blocks)
+	for block := 0; block < blocks; block++ {
+		sums[block], squares[block] = make([]float64, trials), make([]float64, trials)
+		for row := block * size; row < (block+1)*size; row++ {
+			for trial, value := range returns[row] {
+				sums[block][trial] += value
+				squares[block][trial] += value * value
+			}
+		}
+	}
+
+	var logits []float64
+	overfit := 0
+	selected := make([]bool, blocks)
+	eachCombination(blocks, blocks/2, selected, 0, 0, func() {
+		inSample := blockSharpes(sums, squares, selected, true, size)
+		outOfSample := blockSharpes(sums, squares, selected, false, size)
+
+		best := 0
+		for trial := range inSample {
+			if inSample[trial] > inSample[best] {
+				best = trial
+			}
+		}
+
+		rank := 0
+		for _, performance := range outOfSample {
+			if performance <= outOfSample[best] {
+				rank++
+			}
+		}
+		relative := float64(rank) / float64(trials+1)
+		logit := math.Log(relative / (1 - relative))
+		logits = append(logits, logit)
+		if logit <= 0 {
+			overfit++
+		}
+	})
+	return float64(overfit) / float64(len(logits)), logits, nil
+}
+
+//ProbabilisticSharpeRatio is the probability of the true sharpe ratio of the returns being above the benchmark
+//sharpe ratio, adjusting for the length of the track record and the skewness and kurtosis of the returns
+func ProbabilisticSharpeRatio(returns []float64, benchmark float64) float64 {
+	sharpe := sharpeOf(returns)
+	variance := 1 - skewness(returns)*sharpe + (kurtosis(returns)-1)/4*sharpe*sharpe
+	if len(returns) < 2 || variance <= 0 {
+		return math.NaN()
+	}
+	return normalCDF((sharpe - benchmark) * math.Sqrt(float64(len(returns)-1)) / math.Sqrt(variance))
+}
+
+//ExpectedMaxSharpe estimates the maximum sharpe ratio expected from the trials by chance alone
+func ExpectedMaxSharpe(trialSharpes []float64) float64 {
+	trials := float64(len(trialSharpes))
+	if trials < 2 {
+		return 0
+	}
+	deviation := sampleStdDev(trialSharpes)
+	return deviation * ((1-eulerMascheroni)*normalQuantile(1-1/trials) +
+		eulerMascheroni*normalQuantile(1-1/(trials*math.E)))
+}
+
+//DeflatedSharpeRatio is the probabilistic sharpe ratio of the returns using as benchmark the maximum sharpe ratio
+//expected from the amount of trials executed, trialSharpes contains the sharpe ratio of every trial
+func DeflatedSharpeRatio(returns []float64, trialSharpes []float64) float64 {
+	return ProbabilisticSharpeRatio(returns, ExpectedMaxSharpe(trialSharpes))
+}
+
+//eachCombination calls visit for every way of selecting k of the n blocks
+func eachCombination(n, k int, selected []bool, start, chosen int, visit func()) {
+	if chosen == k {
+		visit()
+		return
+	}
+	for i := start; i <= n-(k-chosen); i++ {
+		selected[i] = true
+		eachCombination(n, k, selected, i+1, chosen+1, visit)
+		selected[i] = false
+	}
+}
+
+//blockSharpes calculates the sharpe ratio of each trial over the selected (or not selected) blocks
+func blockSharpes(sums, squares [][]float64, selected []bool, inSample bool, size int) []float64 {
+	sharpes := make([]float64, len(sums[0]))
+	for trial := range sharpes {
+		sum, square, count := 0.0, 0.0, 0
+		for block := range sums {
+			if selected[block] == inSample {
+				sum += sums[block][trial]
+				square += squares[block][trial]
+				count += size
+			}
+		}
+		mean := sum / float64(count)
+		variance := square/float64(count) - mean*mean
+		if variance > 0 {
+			sharpes[trial] = mean / math.Sqrt(variance)
+		}
+	}
+	return sharpes
+}
+
+//sharpeOf is the non annualized sharpe ratio of the returns, zero when the returns don't vary
+func sharpeOf(returns []float64) float64 {
+	deviation := sampleStdDev(returns)
+	if deviation == 0 {
+		return 0
+	}
+	return average(returns) / deviation
+}
+
+func column(matrix [][]float64, index int) []float64 {
+	values := make([]float64, len(matrix))
+	for row := range matrix {
+		values[row] = matrix[row][index]
+	}
+	return values
+}
+
+//normalCDF is the cumulative distribution function of the standard normal distribution
+func normalCDF(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/math.Sqrt2))
+}
+
+//normalQuantile is the inverse of the cumulative distribution function of the standard normal distribution
+func normalQuantile(probability float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*probability-1)
+}
@@ -0,0 +1,122 @@
+package kate
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestProbabilityOfOverfitting(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	noise := make([][]float64, 400)
+	skill := make([][]float64, 400)
+	for row := range noise {
+		noise[row] = make([]float64, 20)
+		skill[row] = make([]float64, 20)
+		for trial := range noise[row] {
+			noise[row][trial] = random.NormFloat64() / 100
+			skill[row][trial] = random.NormFloat64()/100 + float64(trial)/2000
+		}
+	}
+
+	tests := []struct {
+		name     string
+		returns  [][]float64
+		min, max float64
+	}{
+		{"noise", noise, 0.3, 0.7},
+		{"skill", skill, 0, 0.1},
+	}
+	for _, test := range tests {
+		pbo, logits, err := ProbabilityOfOverfitting(test.returns, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logits) != 252 {
+			t.Errorf("%v: expected 252 combinations of 10 blocks, found %v", test.name, len(logits))
+		}
+		if pbo < test.min || pbo > test.max {
+			t.Errorf("%v: expected a probability of overfitting between %v and %v, found %v", test.name, test.min, test.max, pbo)
+		}
+	}
+
+	if _, _, err := ProbabilityOfOverfitting(noise, 5); err == nil {
+		t.Errorf("An odd amount of blocks should be rejected")
+	}
+}
+
+func TestSharpeRatios(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	returns := make([]float64, 1000)
+	for i := range returns {
+		returns[i] = 0.001 + random.NormFloat64()/100
+	}
+
+	if psr := ProbabilisticSharpeRatio(returns, sharpeOf(returns)); !isEqual(psr, 0.5) {
+		t.Errorf("The probabilistic sharpe against its own sharpe ratio should be 0.5, found %v", psr)
+	}
+	if psr := ProbabilisticSharpeRatio(returns, 0); psr < 0.9 {
+		t.Errorf("Returns with positive drift should have a probabilistic sharpe close to 1, found %v", psr)
+	}
+	if short := ProbabilisticSharpeRatio(returns[:100], 0); short >= ProbabilisticSharpeRatio(returns, 0) {
+		t.Errorf("A shorter track record should be less significant, found %v", short)
+	}
+
+	if kurtosis := kurtosis(returns); math.Abs(kurtosis-3) > 0.5 {
+		t.Errorf("Normal returns should have a kurtosis close to 3, found %v", kurtosis)
+	}
+
+	few := []float64{0.01, 0.02, 0.03}
+	many := make([]float64, 1000)
+	for i := range many {
+		many[i] = few[i%3]
+	}
+	if ExpectedMaxSharpe(many) <= ExpectedMaxSharpe(few) {
+		t.Errorf("The expected maximum sharpe should grow with the amount of trials")
+	}
+	if DeflatedSharpeRatio(returns, many) >= ProbabilisticSharpeRatio(returns, 0) {
+		t.Errorf("The deflated sharpe should be lower than the probabilistic sharpe")
+	}
+}
+
+func TestOptimizationOverfitting(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	objective, _ := MetricObjective("Sharpe ratio")
+	search := NewGridSearch(newTargetStrategy, data, objective, IntRange("leverage", 5, 20, 5), FloatRange("target", 0.2, 1, 0.2))
+	search.Setup = func(backtester *Backtester) {
+		backtester.SetBalance(1000)
+		backtester.SetFixedTradeAmount(20)
+	}
+	results, err := search.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matrix := ReturnsMatrix(results)
+	candles := len(results[0].Stats.EquityCurve())
+	if len(matrix) != candles || len(matrix[0]) != len(results) {
+		t.Errorf("Expected a %vx%v returns matrix, found %vx%v", candles, len(results), len(matrix), len(matrix[0]))
+	}
+
+	report, err := results.Overfitting(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Trials != len(results) || len(report.Logits) != 70 || report.PBO < 0 || report.PBO > 1 {
+		t.Errorf("Unexpected overfitting report %+v", report)
+	}
+	if report.DeflatedSharpe > report.ProbabilisticSharpe {
+		t.Errorf("The deflated sharpe %v should not exceed the probabilistic sharpe %v", report.DeflatedSharpe, report.ProbabilisticSharpe)
+	}
+	if table := report.String(); !strings.Contains(table, "Deflated sharpe") {
+		t.Errorf("The report table is missing the deflated sharpe:\n%v", table)
+	}
+
+	if _, err := results[:1].Overfitting(8); err == nil {
+		t.Errorf("A single trial should be rejected")
+	}
+}
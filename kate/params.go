@@ -0,0 +1,134 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+//Params are the values of the parameters used to create a strategy, indexed by the parameter name
+type Params map[string]interface{}
+
+//StrategyFactory creates a new strategy instance configured with the provided parameters
+type StrategyFactory func(params Params) Strategy
+
+//Objective scores the result of a run when optimizing a strategy, higher scores are better
+type Objective func(stats *Statistics) float64
+
+//Parameter is a named dimension of the space searched when optimizing a strategy
+type Parameter struct {
+	Name   string
+	Values []interface{}
+}
+
+//IntRange creates a parameter with every integer between min and max (inclusive) separated by step
+func IntRange(name string, min, max, step int) Parameter {
+	if step <= 0 {
+		step = 1
+	}
+	parameter := Parameter{Name: name}
+	for value := min; value <= max; value += step {
+		parameter.Values = append(parameter.Values, value)
+	}
+	return parameter
+}
+
+//FloatRange creates a parameter with every number between min and max (inclusive) separated by step
+func FloatRange(name string, min, max, step float64) Parameter {
+	parameter := Parameter{Name: name}
+	if step <= 0 {
+		return Parameter{Name: name, Values: []interface{}{min}}
+	}
+	steps := int(math.Floor((max-min)/step + 1e-9))
+	for i := 0; i <= steps; i++ {
+		parameter.Values = append(parameter.Values, min+float64(i)*step)
+	}
+	return parameter
+}
+
+//Choice creates a parameter with a discrete set of options
+func Choice(name string, options ...interface{}) Parameter {
+	return Parameter{Name: name, Values: options}
+}
+
+//Int returns the value of a integer parameter, zero is returned when the parameter is missing
+func (params Params) Int(name string) int {
+	switch value := params[name].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+//Float returns the value of a numeric parameter, zero is returned when the parameter is missing
+func (params Params) Float(name string) float64 {
+	switch value := params[name].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	}
+	return 0
+}
+
+//Text returns the value of a parameter formatted as text
+func (params Params) Text(name string) string {
+	if value, ok := params[name]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+//String renders the parameters sorted by name, e.g. "leverage=10 target=0.5"
+func (params Params) String() string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + params.Text(name)
+	}
+	return strings.Join(pairs, " ")
+}
+
+//MetricObjective creates a objective that ranks runs by one of the MetricNames,
+//metrics where lower values are better (like the drawdown) are negated
+func MetricObjective(name string) (Objective, error) {
+	m, err := (&Statistics{}).findMetric(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(stats *Statistics) float64 {
+		value, _ := stats.Metric(m.name)
+		if m.better == lowerIsBetter {
+			return -value
+		}
+		return value
+	}, nil
+}
+
+//combinations counts how many different parameter sets exist in the space
+func combinations(space []Parameter) int {
+	total := 1
+	for _, parameter := range space {
+		total *= len(parameter.Values)
+	}
+	return total
+}
+
+//paramsAt returns the parameter set for the index of a combination in the space (mixed radix decoding)
+func paramsAt(space []Parameter, index int) Params {
+	params := make(Params, len(space))
+	for i := len(space) - 1; i >= 0; i-- {
+		values := space[i].Values
+		params[space[i].Name] = values[index%len(values)]
+		index /= len(values)
+	}
+	return params
+}
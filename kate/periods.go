@@ -0,0 +1,160 @@
+package kate
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+//PeriodReturn is the performance of the run within a calendar period (day, week, month or year)
+type PeriodReturn struct {
+	Start       time.Time
+	Return      float64 //percentage return of the equity using the equity at the end of the previous period as base
+	NetProfit   float64
+	Trades      int //amount of trades closed within the period
+	WinRate     float64
+	MaxDrawdown float64 //percentage of the maximum drawdown of the equity within the period
+}
+
+//SessionPerformance is the performance of the trades opened in a recurring session (day of week or hour of day)
+type SessionPerformance struct {
+	Session       string
+	Trades        int
+	WinRate       float64
+	NetProfit     float64
+	AverageReturn float64 //average percentage return of the trades relative to their margin
+}
+
+//periodStart maps a time to the start of the period it belongs to
+type periodStart func(time.Time) time.Time
+
+//DailyReturns aggregates the results of the run by day, the result is empty when the data has no timestamps
+func (stats *Statistics) DailyReturns() []PeriodReturn {
+	return stats.periodReturns(func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	})
+}
+
+//WeeklyReturns aggregates the results of the run by week starting on mondays,
+//the result is empty when the data has no timestamps
+func (stats *Statistics) WeeklyReturns() []PeriodReturn {
+	return stats.periodReturns(func(t time.Time) time.Time {
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+//MonthlyReturns aggregates the results of the run by month, the result is empty when the data has no timestamps
+func (stats *Statistics) MonthlyReturns() []PeriodReturn {
+	return stats.periodReturns(func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+//YearlyReturns aggregates the results of the run by year, the result is empty when the data has no timestamps
+func (stats *Statistics) YearlyReturns() []PeriodReturn {
+	return stats.periodReturns(func(t time.Time) time.Time {
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+//DayOfWeekPerformance groups the trades by the weekday they were opened starting on sunday,
+//the result is empty when the data has no timestamps
+func (stats *Statistics) DayOfWeekPerformance() []SessionPerformance {
+	sessions := make([]string, 7)
+	for day := range sessions {
+		sessions[day] = time.Weekday(day).String()
+	}
+	return stats.sessionPerformance(sessions, func(t time.Time) int { return int(t.Weekday()) })
+}
+
+//HourOfDayPerformance groups the trades by the hour (UTC) they were opened,
+//the result is empty when the data has no timestamps
+func (stats *Statistics) HourOfDayPerformance() []SessionPerformance {
+	sessions := make([]string, 24)
+	for hour := range sessions {
+		sessions[hour] = strconv.Itoa(hour)
+	}
+	return stats.sessionPerformance(sessions, func(t time.Time) int { return t.Hour() })
+}
+
+//periodReturns groups the equity curve and trades of the run in the periods defined by the start function
+func (stats *Statistics) periodReturns(start periodStart) []PeriodReturn {
+	if len(stats.equityCurve) == 0 || stats.equityCurve[0].Time.IsZero() {
+		return nil
+	}
+
+	var periods []PeriodReturn
+	base, peak := stats.initialBalance, stats.initialBalance
+	for i, point := range stats.equityCurve {
+		current := start(point.Time)
+		if len(periods) == 0 || !periods[len(periods)-1].Start.Equal(current) {
+			periods = append(periods, PeriodReturn{Start: current})
+			peak = base
+		}
+
+		period := &periods[len(periods)-1]
+		peak = math.Max(peak, point.Equity)
+		if peak > 0 {
+			period.MaxDrawdown = math.Max(period.MaxDrawdown, 100*(peak-point.Equity)/peak)
+		}
+
+		isLast := i == len(stats.equityCurve)-1
+		if isLast || !start(stats.equityCurve[i+1].Time).Equal(current) {
+			period.NetProfit = point.Equity - base
+			period.Return = 100 * (point.Equity/base - 1)
+			base = point.Equity
+		}
+	}
+
+	indexes := make(map[time.Time]int, len(periods))
+	for i, period := range periods {
+		indexes[period.Start] = i
+	}
+	wins := make([]int, len(periods))
+	for _, trade := range stats.trades {
+		if i, ok := indexes[start(trade.CloseTime)]; ok {
+			periods[i].Trades++
+			if trade.RealizedPNL >= 0 {
+				wins[i]++
+			}
+		}
+	}
+	for i := range periods {
+		if periods[i].Trades > 0 {
+			periods[i].WinRate = float64(wins[i]) / float64(periods[i].Trades)
+		}
+	}
+	return periods
+}
+
+//sessionPerformance groups the trades by the session of their entry time
+func (stats *Statistics) sessionPerformance(sessions []string, session func(time.Time) int) []SessionPerformance {
+	if len(stats.equityCurve) == 0 || stats.equityCurve[0].Time.IsZero() {
+		return nil
+	}
+
+	results := make([]SessionPerformance, len(sessions))
+	wins := make([]int, len(sessions))
+	for i, name := range sessions {
+		results[i].Session = name
+	}
+
+	for _, trade := range stats.trades {
+		result := &results[session(trade.EntryTime)]
+		result.Trades++
+		result.NetProfit += trade.RealizedPNL
+		result.AverageReturn += tradeReturn(trade)
+		if trade.RealizedPNL >= 0 {
+			wins[session(trade.EntryTime)]++
+		}
+	}
+
+	for i := range results {
+		if results[i].Trades > 0 {
+			results[i].WinRate = float64(wins[i]) / float64(results[i].Trades)
+			results[i].AverageReturn /= float64(results[i].Trades)
+		}
+	}
+	return results
+}
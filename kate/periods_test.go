@@ -0,0 +1,91 @@
+package kate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodReturns(t *testing.T) {
+	stats := &Statistics{initialBalance: 100,
+		equityCurve: []EquityPoint{
+			{Time: time.Date(2021, time.January, 30, 10, 0, 0, 0, time.UTC), Equity: 105},
+			{Time: time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC), Equity: 110},
+			{Time: time.Date(2021, time.February, 1, 10, 0, 0, 0, time.UTC), Equity: 88},
+			{Time: time.Date(2021, time.February, 2, 10, 0, 0, 0, time.UTC), Equity: 99},
+			{Time: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC), Equity: 99},
+		},
+		trades: []Position{
+			{RealizedPNL: 10, CloseTime: time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)},
+			{RealizedPNL: -22, CloseTime: time.Date(2021, time.February, 1, 10, 0, 0, 0, time.UTC)},
+			{RealizedPNL: 11, CloseTime: time.Date(2021, time.February, 2, 10, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		periods  []PeriodReturn
+		expected []PeriodReturn
+	}{
+		{"monthly", stats.MonthlyReturns(), []PeriodReturn{
+			{Start: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), Return: 10, NetProfit: 10, Trades: 1, WinRate: 1},
+			{Start: time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC), Return: -10, NetProfit: -11, Trades: 2, WinRate: 0.5, MaxDrawdown: 20},
+			{Start: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), Return: 0, NetProfit: 0},
+		}},
+		{"weekly", stats.WeeklyReturns(), []PeriodReturn{
+			{Start: time.Date(2021, time.January, 25, 0, 0, 0, 0, time.UTC), Return: 10, NetProfit: 10, Trades: 1, WinRate: 1},
+			{Start: time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC), Return: -10, NetProfit: -11, Trades: 2, WinRate: 0.5, MaxDrawdown: 20},
+			{Start: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), Return: 0, NetProfit: 0},
+		}},
+		{"yearly", stats.YearlyReturns(), []PeriodReturn{
+			{Start: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), Return: -1, NetProfit: -1, Trades: 3, WinRate: 2.0 / 3, MaxDrawdown: 20},
+		}},
+	}
+
+	for _, test := range tests {
+		if len(test.periods) != len(test.expected) {
+			t.Errorf("The %v returns contain %v periods the expected amount is %v", test.name, len(test.periods), len(test.expected))
+			continue
+		}
+		for i, period := range test.periods {
+			expected := test.expected[i]
+			if !period.Start.Equal(expected.Start) || !isEqual(period.Return, expected.Return) ||
+				!isEqual(period.NetProfit, expected.NetProfit) || period.Trades != expected.Trades ||
+				!isEqual(period.WinRate, expected.WinRate) || !isEqual(period.MaxDrawdown, expected.MaxDrawdown) {
+				t.Errorf("The %v period %v is %+v the expected was %+v", test.name, i, period, expected)
+			}
+		}
+	}
+
+	if daily := stats.DailyReturns(); len(daily) != 5 {
+		t.Errorf("The amount of daily returns is %v the expected amount is 5", len(daily))
+	}
+}
+
+func TestSessionPerformance(t *testing.T) {
+	monday := time.Date(2021, time.February, 1, 14, 30, 0, 0, time.UTC)
+	stats := &Statistics{
+		equityCurve: []EquityPoint{{Time: monday}},
+		trades: []Position{
+			{RealizedPNL: 5, EntryTime: monday, Margin: 1, EntryPrice: 100},
+			{RealizedPNL: -1, EntryTime: monday.Add(time.Minute), Margin: 1, EntryPrice: 100},
+			{RealizedPNL: 2, EntryTime: monday.Add(24 * time.Hour), Margin: 1, EntryPrice: 100},
+		},
+	}
+
+	weekdays := stats.DayOfWeekPerformance()
+	if len(weekdays) != 7 || weekdays[time.Monday].Trades != 2 || weekdays[time.Tuesday].Trades != 1 ||
+		!isEqual(weekdays[time.Monday].WinRate, 0.5) || !isEqual(weekdays[time.Monday].NetProfit, 4) ||
+		!isEqual(weekdays[time.Monday].AverageReturn, 2) {
+		t.Errorf("The performance by day of week contains wrong values: %+v", weekdays)
+	}
+
+	hours := stats.HourOfDayPerformance()
+	if len(hours) != 24 || hours[14].Trades != 3 || hours[14].Session != "14" || hours[13].Trades != 0 {
+		t.Errorf("The performance by hour of day contains wrong values: %+v", hours)
+	}
+
+	if withoutTime := (&Statistics{equityCurve: []EquityPoint{{}}}); withoutTime.MonthlyReturns() != nil ||
+		withoutTime.HourOfDayPerformance() != nil {
+		t.Errorf("No periodic results should be produced for price data without timestamps")
+	}
+}
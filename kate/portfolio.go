@@ -0,0 +1,193 @@
+package kate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//PortfolioStrategy defines how/when trades should be opened on each symbol of a portfolio.
+//The callbacks are the same of Strategy receiving the symbol of the price data or position.
+type PortfolioStrategy interface {
+	PreProcessIndicators(symbol string, latestPrice DataPoint)
+	OpenNewPosition(symbol string, latestPrice DataPoint) *OpenPositionEvt
+	SetStoploss(symbol string, openPosition Position) *StoplossEvt
+	SetTakeProfit(symbol string, openPosition Position) *TakeProfitEvt
+}
+
+//PortfolioBacktester runs a strategy on a basket of symbols sharing a single balance.
+//Each symbol can have a single open position at a time and the margin of the open positions is reserved from the
+//shared balance until they are closed. The lookback, position sizers, risk managers and the optional interfaces
+//and hooks of Strategy are not supported on portfolio runs.
+type PortfolioBacktester struct {
+	newStrategy     func() PortfolioStrategy
+	exchangeHandler *ExchangeHandler //configuration of the exchange, copied for each symbol at the start of every run
+	feeds           map[string]*DataHandler
+	symbols         []string //symbols sorted alphabetically, the order the candles of each step are processed
+}
+
+//PortfolioStatistics are the results of a portfolio run
+type PortfolioStatistics struct {
+	Portfolio *Statistics            //metrics of all trades combined and the equity of the shared balance
+	Symbols   map[string]*Statistics //metrics of the trades of each symbol relative to the shared initial balance
+}
+
+//symbolStrategy adapts a portfolio strategy to the Strategy used by the simulation of a single symbol
+type symbolStrategy struct {
+	symbol   string
+	strategy PortfolioStrategy
+}
+
+//NewPortfolioBacktester creates a backtester for the feeds keyed by symbol. The feeds must be aligned:
+//the same amount of candles and, when the data has timestamps, the same time on each candle.
+func NewPortfolioBacktester(newStrategy func() PortfolioStrategy, feeds map[string]*DataHandler) (*PortfolioBacktester, error) {
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("the portfolio requires at least one data feed")
+	}
+
+	symbols := make([]string, 0, len(feeds))
+	for symbol := range feeds {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	reference := feeds[symbols[0]].Prices
+	for _, symbol := range symbols[1:] {
+		prices := feeds[symbol].Prices
+		if len(prices) != len(reference) {
+			return nil, fmt.Errorf("the feed %v has %v candles while %v has %v", symbol, len(prices), symbols[0], len(reference))
+		}
+		for i := range prices {
+			if !prices[i].Time().Equal(reference[i].Time()) {
+				return nil, fmt.Errorf("the candle %v of %v closes at %v while %v closes at %v", i, symbol,
+					prices[i].Time(), symbols[0], reference[i].Time())
+			}
+		}
+	}
+
+	return &PortfolioBacktester{
+		newStrategy:     newStrategy,
+		exchangeHandler: NewExchangeHandler(USDFutures, 0.02, 0.04, 1),
+		feeds:           feeds,
+		symbols:         symbols,
+	}, nil
+}
+
+//SetBalance defines the initial balance shared by all symbols
+func (pb *PortfolioBacktester) SetBalance(amount float64) {
+	pb.exchangeHandler.balance = amount
+}
+
+//SetFixedTradeAmount defines a fixed value that will be used to open every position when trading
+func (pb *PortfolioBacktester) SetFixedTradeAmount(amount float64) {
+	pb.exchangeHandler.fixedTradeAmount = amount
+}
+
+//SetSlippagePercentage define a slippage that tries to better emulate the real trading market
+func (pb *PortfolioBacktester) SetSlippagePercentage(slippagePercent float64) {
+	pb.exchangeHandler.SetSlipage(slippagePercent)
+}
+
+//SetFundingRate defines the funding rate charged from open positions on every interval (usually 8 hours)
+func (pb *PortfolioBacktester) SetFundingRate(ratePercent float64, interval time.Duration) {
+	pb.exchangeHandler.SetFundingRate(ratePercent, interval)
+}
+
+//Run executes the simulation processing the candle of every symbol on each step before moving to the next step
+func (pb *PortfolioBacktester) Run() *PortfolioStatistics {
+	strategy := pb.newStrategy()
+	initialBalance := pb.exchangeHandler.balance
+	balance := initialBalance
+
+	simulations := make([]*simulation, len(pb.symbols))
+	for i, symbol := range pb.symbols {
+		simulations[i] = &simulation{
+			myStrategy:      &symbolStrategy{symbol: symbol, strategy: strategy},
+			exchangeHandler: pb.exchangeHandler.reset(),
+			dataHandler:     pb.feeds[symbol],
+			history:         NewSeries(defaultHistory),
+		}
+	}
+
+	var equityCurve []EquityPoint
+	for step := range pb.feeds[pb.symbols[0]].Prices {
+		for _, sim := range simulations {
+			//the free balance is handed to each symbol so every position is opened and closed on the shared balance
+			//without using the margin reserved by the open positions
+			free := balance
+			for _, other := range simulations {
+				free -= other.exchangeHandler.usedMargin()
+			}
+			sim.exchangeHandler.balance = free
+			for sim.eventQueue.HasNext() {
+				sim.processNextEvent()
+			}
+			sim.eventQueue.AddEvent(sim.dataHandler.Prices[step])
+			balance += sim.exchangeHandler.balance - free
+		}
+
+		if step > 0 {
+			equity := balance
+			for _, sim := range simulations {
+				equity += sim.exchangeHandler.equity() - sim.exchangeHandler.balance
+			}
+			equityCurve = append(equityCurve, EquityPoint{Candle: step - 1,
+				Time: pb.feeds[pb.symbols[0]].Prices[step-1].Time(), Balance: balance, Equity: equity})
+		}
+	}
+
+	return pb.statistics(simulations, initialBalance, equityCurve)
+}
+
+//statistics combines the trades and orders of every symbol ordered by the candle they happened
+func (pb *PortfolioBacktester) statistics(simulations []*simulation, initialBalance float64,
+	equityCurve []EquityPoint) *PortfolioStatistics {
+	result := &PortfolioStatistics{Symbols: map[string]*Statistics{}}
+	var trades []Position
+	var orders []Order
+
+	for i, sim := range simulations {
+		stats := sim.calculateStatistics(initialBalance)
+		for j := range stats.trades {
+			stats.trades[j].Symbol = pb.symbols[i]
+		}
+		for j := range stats.orders {
+			stats.orders[j].Symbol = pb.symbols[i]
+		}
+		result.Symbols[pb.symbols[i]] = stats
+		trades = append(trades, stats.trades...)
+		orders = append(orders, stats.orders...)
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].CloseCandle < trades[j].CloseCandle
+	})
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].Candle < orders[j].Candle
+	})
+
+	result.Portfolio = statisticsFromTrades(initialBalance, trades)
+	result.Portfolio.TotalDataPoints = len(pb.feeds[pb.symbols[0]].Prices)
+	result.Portfolio.orders = orders
+	result.Portfolio.equityCurve = equityCurve
+	result.Portfolio.market = pb.exchangeHandler.marketHandler
+	return result
+}
+
+func (adapter *symbolStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	adapter.strategy.PreProcessIndicators(adapter.symbol, latestPrice)
+}
+
+func (adapter *symbolStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	return adapter.strategy.OpenNewPosition(adapter.symbol, latestPrice)
+}
+
+func (adapter *symbolStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	openPosition.Symbol = adapter.symbol
+	return adapter.strategy.SetStoploss(adapter.symbol, openPosition)
+}
+
+func (adapter *symbolStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	openPosition.Symbol = adapter.symbol
+	return adapter.strategy.SetTakeProfit(adapter.symbol, openPosition)
+}
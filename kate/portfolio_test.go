@@ -0,0 +1,181 @@
+package kate
+
+import (
+	"testing"
+)
+
+//portfolioSimpleStrategy runs a simpleStrategy for each symbol
+type portfolioSimpleStrategy struct {
+	strategies map[string]*simpleStrategy
+}
+
+func newPortfolioSimpleStrategy() PortfolioStrategy {
+	return &portfolioSimpleStrategy{strategies: map[string]*simpleStrategy{}}
+}
+
+func (portfolio *portfolioSimpleStrategy) PreProcessIndicators(symbol string, latestPrice DataPoint) {
+	if portfolio.strategies[symbol] == nil {
+		portfolio.strategies[symbol] = newSimpleStrategy()
+	}
+	portfolio.strategies[symbol].PreProcessIndicators(latestPrice)
+}
+
+func (portfolio *portfolioSimpleStrategy) OpenNewPosition(symbol string, latestPrice DataPoint) *OpenPositionEvt {
+	return portfolio.strategies[symbol].OpenNewPosition(latestPrice)
+}
+
+func (portfolio *portfolioSimpleStrategy) SetStoploss(symbol string, openPosition Position) *StoplossEvt {
+	return portfolio.strategies[symbol].SetStoploss(openPosition)
+}
+
+func (portfolio *portfolioSimpleStrategy) SetTakeProfit(symbol string, openPosition Position) *TakeProfitEvt {
+	if openPosition.Symbol != symbol {
+		panic("the position should belong to the symbol " + symbol)
+	}
+	return portfolio.strategies[symbol].SetTakeProfit(openPosition)
+}
+
+func TestPortfolioBacktester(t *testing.T) {
+	eth, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	long, err := PricesFromCSV("../testdata/ETHUSD3.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	altPrices := make([]DataPoint, len(eth.Prices))
+	for i := range altPrices {
+		altPrices[i] = long.Prices[i]
+		altPrices[i].timestamp = eth.Prices[i].timestamp
+	}
+	alt := newDataHandler(altPrices)
+
+	portfolio, err := NewPortfolioBacktester(newPortfolioSimpleStrategy, map[string]*DataHandler{"ETH": eth, "ALT": alt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	portfolio.SetBalance(1000)
+	portfolio.SetFixedTradeAmount(20)
+	result := portfolio.Run()
+
+	netProfit, trades := 0.0, 0
+	for symbol, data := range map[string]*DataHandler{"ETH": eth, "ALT": alt} {
+		backtester := NewBacktester(newSimpleStrategy(), data)
+		backtester.SetBalance(1000)
+		backtester.SetFixedTradeAmount(20)
+		expected := backtester.Run()
+
+		stats := result.Symbols[symbol]
+		if stats.TotalTrades != expected.TotalTrades || !isEqual(stats.NetProfit, expected.NetProfit) {
+			t.Errorf("%v: expected %v trades and %v net profit, found %v and %v", symbol, expected.TotalTrades,
+				expected.NetProfit, stats.TotalTrades, stats.NetProfit)
+		}
+		for _, trade := range stats.Trades() {
+			if trade.Symbol != symbol {
+				t.Errorf("%v: found a trade of %v", symbol, trade.Symbol)
+			}
+		}
+		netProfit += expected.NetProfit
+		trades += expected.TotalTrades
+	}
+
+	stats := result.Portfolio
+	if stats.TotalTrades != trades || !isEqual(stats.NetProfit, netProfit) {
+		t.Errorf("Expected the portfolio to have %v trades and %v net profit, found %v and %v", trades, netProfit,
+			stats.TotalTrades, stats.NetProfit)
+	}
+	for i := 1; i < len(stats.Trades()); i++ {
+		if stats.Trades()[i].CloseCandle < stats.Trades()[i-1].CloseCandle {
+			t.Fatalf("The portfolio trades should be ordered by the close candle")
+		}
+	}
+	curve := stats.EquityCurve()
+	if len(curve) != len(eth.Prices)-1 || !isEqual(curve[len(curve)-1].Balance, 1000+netProfit) {
+		t.Errorf("Unexpected portfolio equity curve with %v points ending at %+v", len(curve), curve[len(curve)-1])
+	}
+
+	misaligned := []*DataHandler{long, newDataHandler(long.Prices[:len(eth.Prices)])}
+	for _, feed := range misaligned {
+		if _, err := NewPortfolioBacktester(newPortfolioSimpleStrategy, map[string]*DataHandler{"ETH": eth, "ALT": feed}); err == nil {
+			t.Errorf("Feeds that are not aligned should be rejected")
+		}
+	}
+}
+
+//portfolioEntryStrategy opens a long position on every symbol on the same candle and holds it
+type portfolioEntryStrategy struct {
+	candles map[string]int
+}
+
+func (portfolio *portfolioEntryStrategy) PreProcessIndicators(symbol string, latestPrice DataPoint) {
+	portfolio.candles[symbol]++
+}
+
+func (portfolio *portfolioEntryStrategy) OpenNewPosition(symbol string, latestPrice DataPoint) *OpenPositionEvt {
+	if portfolio.candles[symbol] == 10 {
+		return &OpenPositionEvt{Direction: LONG, Leverage: 5}
+	}
+	return nil
+}
+
+func (portfolio *portfolioEntryStrategy) SetStoploss(symbol string, openPosition Position) *StoplossEvt {
+	return nil
+}
+
+func (portfolio *portfolioEntryStrategy) SetTakeProfit(symbol string, openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+func TestPortfolioSharedMargin(t *testing.T) {
+	eth, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	feeds := map[string]*DataHandler{"A": eth, "B": eth, "C": eth}
+
+	var tests = []struct {
+		amount float64
+		opened []string
+	}{
+		{300, []string{"A", "B", "C"}},
+		{400, []string{"A", "B"}},
+		{600, []string{"A"}},
+	}
+	for _, test := range tests {
+		portfolio, err := NewPortfolioBacktester(func() PortfolioStrategy {
+			return &portfolioEntryStrategy{candles: map[string]int{}}
+		}, feeds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		portfolio.SetBalance(1000)
+		portfolio.SetFixedTradeAmount(test.amount)
+		result := portfolio.Run()
+
+		var opened []string
+		margin := 0.0
+		for _, order := range result.Portfolio.Orders() {
+			if order.Action != OpenAction {
+				continue
+			}
+			if order.Status == Filled {
+				opened = append(opened, order.Symbol)
+				margin += test.amount
+			} else if order.Status != Rejected || order.Error == "" {
+				t.Errorf("amount %v: the order of %v should be rejected with the reason, found %+v", test.amount,
+					order.Symbol, order)
+			}
+		}
+		if len(opened) != len(test.opened) || margin > 1000 {
+			t.Errorf("amount %v: expected the positions of %v sharing the margin, found %v using %v", test.amount,
+				test.opened, opened, margin)
+			continue
+		}
+		for i := range opened {
+			if opened[i] != test.opened[i] {
+				t.Errorf("amount %v: expected the positions of %v, found %v", test.amount, test.opened, opened)
+			}
+		}
+	}
+}
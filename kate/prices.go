@@ -1,9 +1,12 @@
 package kate
 
+import "time"
+
 //DataPoint is a unit that encapsulates OHLCV price data
 type DataPoint struct {
 	Event
 	open, high, low, close, volume float64
+	timestamp                      time.Time
 }
 
 //OHLCV - Represents a datapoint in candle format that contain Open,High, Low, Close prices and Volume data
@@ -22,6 +25,14 @@ type OHLCV interface {
 
 	//Volume is the amount of assets traded in the timeframe for the current candlestick
 	Volume() float64
+
+	//Time is the moment when the candlestick was closed, a zero value denotes that the data has no timestamps
+	Time() time.Time
+}
+
+//NewDataPoint creates a candlestick with the provided OHLCV prices closed at the given time
+func NewDataPoint(open, high, low, close, volume float64, closeTime time.Time) DataPoint {
+	return DataPoint{open: open, high: high, low: low, close: close, volume: volume, timestamp: closeTime}
 }
 
 //Open is the starting price for a candlestick
@@ -48,3 +59,8 @@ func (candle DataPoint) Close() float64 {
 func (candle DataPoint) Volume() float64 {
 	return candle.volume
 }
+
+//Time is the moment when the candlestick was closed, a zero value denotes that the data has no timestamps
+func (candle DataPoint) Time() time.Time {
+	return candle.timestamp
+}
@@ -0,0 +1,361 @@
+package kate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+//defaultProcessTimeout is how long the process has to read each message and answer each candle
+const defaultProcessTimeout = 10 * time.Second
+
+//ProcessStrategy runs the strategy in a external process, written in any language, exchanging newline-delimited
+//json messages through its stdin and stdout. A new process is started on every run and the process must answer
+//each candle message with a single line containing its intents. The first error of the process (a timeout,
+//invalid answer, error reported by the process or unexpected exit) stops the process, no more positions are
+//opened for the rest of the run and the error is available with Err.
+type ProcessStrategy struct {
+	Timeout time.Duration //maximum time to read each message, answer a candle and exit after the end of the run
+	Env     []string      //variables added to the environment of the process (KEY=value)
+
+	name string
+	args []string
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	lines   chan []byte
+	stderr  *bytes.Buffer
+	err     error
+	balance float64
+	candle  int
+	latest  DataPoint
+	pending bool            //the latest candle was not sent to the process yet
+	intents *processIntents //answer of the process to the latest candle
+}
+
+//processMessage is a message sent to the process, only the fields of its type are included:
+//start (config), candle (candle, balance and the open position), order (order), closed (position) and end (summary)
+type processMessage struct {
+	Type     string           `json:"type"`
+	Config   *processConfig   `json:"config,omitempty"`
+	Candle   *processCandle   `json:"candle,omitempty"`
+	Balance  *number          `json:"balance,omitempty"`
+	Position *processPosition `json:"position,omitempty"`
+	Order    *orderRecord     `json:"order,omitempty"`
+	Summary  *summaryRecord   `json:"summary,omitempty"`
+}
+
+type processConfig struct {
+	InitialBalance           number   `json:"initial_balance"`
+	MakerFeePercentage       number   `json:"maker_fee_percentage"`
+	TakerFeePercentage       number   `json:"taker_fee_percentage"`
+	SlippagePercentage       number   `json:"slippage_percentage"`
+	AmountPerTradePercentage number   `json:"amount_per_trade_percentage"`
+	FixedTradeAmount         number   `json:"fixed_trade_amount"`
+	Lookback                 int      `json:"lookback"`
+	DataPoints               int      `json:"data_points"`
+	Timeframes               []string `json:"timeframes"`
+}
+
+type processCandle struct {
+	Index  int    `json:"index"`
+	Time   string `json:"time"`
+	Open   number `json:"open"`
+	High   number `json:"high"`
+	Low    number `json:"low"`
+	Close  number `json:"close"`
+	Volume number `json:"volume"`
+}
+
+//processPosition is a position with the same fields of the exported trades and its unrealized pnl
+type processPosition struct {
+	tradeRecord
+	UnrealizedPNL number `json:"unrealized_pnl"`
+}
+
+//processIntents is the answer of the process to a candle, every field is optional
+type processIntents struct {
+	Open *struct {
+		Direction string  `json:"direction"` //long or short
+		Leverage  uint    `json:"leverage"`
+		Size      float64 `json:"size"`
+		Stop      float64 `json:"stop"`
+		Target    float64 `json:"target"`
+	} `json:"open"` //opens a position when there is none open (or it was closed on the same candle)
+	Close  bool    `json:"close"`  //closes the open position with a market order
+	Stop   float64 `json:"stop"`   //new stoploss of the open position
+	Target float64 `json:"target"` //new takeprofit of the open position
+	Error  string  `json:"error"`  //stops the run reporting the error
+}
+
+//NewProcessStrategy creates a strategy that runs the command with the arguments on every run
+func NewProcessStrategy(name string, args ...string) *ProcessStrategy {
+	return &ProcessStrategy{Timeout: defaultProcessTimeout, name: name, args: args}
+}
+
+//Err returns the first error of the process on the latest run
+func (strategy *ProcessStrategy) Err() error {
+	return strategy.err
+}
+
+//OnStart starts the process and sends the configuration of the run
+func (strategy *ProcessStrategy) OnStart(config RunConfig) {
+	strategy.err, strategy.pending, strategy.intents = nil, false, nil
+	strategy.balance, strategy.candle = config.InitialBalance, -1
+	strategy.stdin, strategy.stderr = nil, &bytes.Buffer{}
+
+	cmd := exec.Command(strategy.name, strategy.args...)
+	cmd.Env = append(os.Environ(), strategy.Env...)
+	cmd.Stderr = strategy.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		strategy.fail(err)
+		return
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		strategy.fail(err)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		strategy.fail(fmt.Errorf("could not start the process %v: %v", strategy.name, err))
+		return
+	}
+	strategy.cmd, strategy.stdin = cmd, stdin
+
+	strategy.lines = make(chan []byte)
+	go func(lines chan []byte) {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			lines <- append([]byte{}, scanner.Bytes()...)
+		}
+		close(lines)
+	}(strategy.lines)
+
+	strategy.send(processMessage{Type: "start", Config: &processConfig{
+		InitialBalance:           number(config.InitialBalance),
+		MakerFeePercentage:       number(config.MakerFeePercentage),
+		TakerFeePercentage:       number(config.TakerFeePercentage),
+		SlippagePercentage:       number(config.SlippagePercentage),
+		AmountPerTradePercentage: number(config.AmountPerTradePercentage),
+		FixedTradeAmount:         number(config.FixedTradeAmount),
+		Lookback:                 config.Lookback,
+		DataPoints:               config.DataPoints,
+		Timeframes:               config.Timeframes,
+	}})
+}
+
+//OnEnd sends the summary of the run and waits for the process to exit
+func (strategy *ProcessStrategy) OnEnd(stats *Statistics) {
+	strategy.flush()
+	summary := stats.summaryRecord()
+	strategy.send(processMessage{Type: "end", Summary: &summary})
+	if strategy.err != nil || strategy.cmd == nil {
+		return
+	}
+
+	strategy.stdin.Close()
+	deadline := time.After(strategy.Timeout)
+	for {
+		select {
+		case _, open := <-strategy.lines:
+			if open {
+				continue
+			}
+			err := strategy.cmd.Wait()
+			strategy.cmd = nil
+			if err != nil {
+				strategy.fail(fmt.Errorf("the process exited with error: %v", err))
+			}
+			return
+		case <-deadline:
+			strategy.fail(fmt.Errorf("the process didn't exit %v after the end of the run", strategy.Timeout))
+			return
+		}
+	}
+}
+
+//OnOrderFilled sends the filled order to the process
+func (strategy *ProcessStrategy) OnOrderFilled(order Order) {
+	record := newOrderRecord(order)
+	strategy.send(processMessage{Type: "order", Order: &record})
+}
+
+//OnPositionClosed sends the closed position to the process
+func (strategy *ProcessStrategy) OnPositionClosed(position Position, reason ExitReason) {
+	strategy.balance += position.RealizedPNL
+	strategy.send(processMessage{Type: "closed", Position: newProcessPosition(position)})
+}
+
+//PreProcessIndicators keeps the candle to be sent with the open position on the first decision of the candle
+func (strategy *ProcessStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	strategy.flush()
+	strategy.latest, strategy.pending, strategy.intents = latestPrice, true, nil
+	strategy.candle++
+}
+
+//ClosePosition closes the open position when requested by the process
+func (strategy *ProcessStrategy) ClosePosition(openPosition Position) bool {
+	return strategy.exchange(&openPosition).Close
+}
+
+//OpenNewPosition opens the position requested by the process
+func (strategy *ProcessStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	open := strategy.exchange(nil).Open
+	if open == nil {
+		return nil
+	}
+
+	direction := LONG
+	switch strings.ToLower(open.Direction) {
+	case "long":
+	case "short":
+		direction = SHORT
+	default:
+		strategy.fail(fmt.Errorf("invalid direction '%v' requested on candle %v, expected long or short",
+			open.Direction, strategy.candle))
+		return nil
+	}
+	return &OpenPositionEvt{Direction: direction, Leverage: open.Leverage, Quantity: open.Size, Stoploss: open.Stop,
+		TakeProfit: open.Target}
+}
+
+//SetStoploss moves the stoploss when requested by the process
+func (strategy *ProcessStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	if stop := strategy.exchange(&openPosition).Stop; stop > 0 && stop != openPosition.Stoploss {
+		return &StoplossEvt{Price: stop}
+	}
+	return nil
+}
+
+//SetTakeProfit moves the takeprofit when requested by the process
+func (strategy *ProcessStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	if target := strategy.exchange(&openPosition).Target; target > 0 && target != openPosition.TakeProfit {
+		return &TakeProfitEvt{Price: target}
+	}
+	return nil
+}
+
+//exchange sends the latest candle with the open position and reads the intents of the process,
+//the intents are kept for the other decisions of the same candle
+func (strategy *ProcessStrategy) exchange(openPosition *Position) *processIntents {
+	if strategy.intents != nil {
+		return strategy.intents
+	}
+	strategy.intents, strategy.pending = &processIntents{}, false
+	if strategy.err != nil {
+		return strategy.intents
+	}
+
+	candle := strategy.latest
+	balance := number(strategy.balance)
+	message := processMessage{Type: "candle", Balance: &balance, Candle: &processCandle{
+		Index:  strategy.candle,
+		Time:   formatTime(candle.Time()),
+		Open:   number(candle.Open()),
+		High:   number(candle.High()),
+		Low:    number(candle.Low()),
+		Close:  number(candle.Close()),
+		Volume: number(candle.Volume()),
+	}}
+	if openPosition != nil {
+		message.Position = newProcessPosition(*openPosition)
+	}
+	if !strategy.send(message) {
+		return strategy.intents
+	}
+
+	select {
+	case line, open := <-strategy.lines:
+		if !open {
+			strategy.fail(fmt.Errorf("the process closed its output before answering the candle %v", strategy.candle))
+			return strategy.intents
+		}
+		var intents processIntents
+		if err := json.Unmarshal(line, &intents); err != nil {
+			strategy.fail(fmt.Errorf("invalid answer to the candle %v '%s': %v", strategy.candle, line, err))
+			return strategy.intents
+		}
+		if intents.Error != "" {
+			strategy.fail(fmt.Errorf("the process reported an error on the candle %v: %v", strategy.candle, intents.Error))
+			return strategy.intents
+		}
+		strategy.intents = &intents
+	case <-time.After(strategy.Timeout):
+		strategy.fail(fmt.Errorf("the process didn't answer the candle %v in %v", strategy.candle, strategy.Timeout))
+	}
+	return strategy.intents
+}
+
+//flush sends the latest candle when no decision was requested on it (while the lookback is filled),
+//the intents of the process are ignored
+func (strategy *ProcessStrategy) flush() {
+	if strategy.pending {
+		strategy.exchange(nil)
+	}
+}
+
+//send writes the message as a single line, false is returned when the process can't receive it
+func (strategy *ProcessStrategy) send(message processMessage) bool {
+	if strategy.err != nil {
+		return false
+	}
+	if strategy.stdin == nil {
+		strategy.fail(fmt.Errorf("the process was not started, the strategy must be run by a Backtester"))
+		return false
+	}
+	line, err := json.Marshal(message)
+	if err != nil {
+		strategy.fail(fmt.Errorf("could not send the %v message to the process: %v", message.Type, err))
+		return false
+	}
+
+	//the write blocks once the pipe is full, a process that stops reading its stdin is stopped by the timeout
+	written := make(chan error, 1)
+	go func(stdin io.Writer) {
+		_, err := stdin.Write(append(line, '\n'))
+		written <- err
+	}(strategy.stdin)
+	select {
+	case err := <-written:
+		if err != nil {
+			strategy.fail(fmt.Errorf("could not send the %v message to the process: %v", message.Type, err))
+			return false
+		}
+		return true
+	case <-time.After(strategy.Timeout):
+		strategy.fail(fmt.Errorf("the process didn't read the %v message in %v", message.Type, strategy.Timeout))
+		return false
+	}
+}
+
+//fail keeps the first error and stops the process, the stderr of the process is included in the error
+func (strategy *ProcessStrategy) fail(err error) {
+	if strategy.err != nil {
+		return
+	}
+	if strategy.cmd != nil {
+		strategy.cmd.Process.Kill()
+		go func(lines chan []byte) {
+			for range lines {
+			}
+		}(strategy.lines)
+		strategy.cmd.Wait()
+		strategy.cmd = nil
+	}
+	if output := strings.TrimSpace(strategy.stderr.String()); output != "" {
+		err = fmt.Errorf("%v\n%v", err, output)
+	}
+	strategy.err = err
+}
+
+func newProcessPosition(position Position) *processPosition {
+	return &processPosition{tradeRecord: newTradeRecord(position), UnrealizedPNL: number(position.UnrealizedPNL)}
+}
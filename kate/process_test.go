@@ -0,0 +1,207 @@
+package kate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+//holdingStrategy is the in process version of the momentum mode of the helper process
+type holdingStrategy struct {
+	candle    int
+	lastClose float64
+	close     float64
+}
+
+func (strategy *holdingStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	strategy.candle++
+	strategy.lastClose, strategy.close = strategy.close, latestPrice.Close()
+}
+
+func (strategy *holdingStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	if strategy.lastClose > 0 && strategy.close > strategy.lastClose {
+		return &OpenPositionEvt{Direction: LONG, Leverage: 10, Stoploss: strategy.close * 0.99,
+			TakeProfit: strategy.close * 1.01}
+	}
+	return nil
+}
+
+func (strategy *holdingStrategy) ClosePosition(openPosition Position) bool {
+	return strategy.candle-openPosition.EntryCandle >= 5
+}
+
+func (strategy *holdingStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	return nil
+}
+
+func (strategy *holdingStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+//TestProcessHelper is the process started by the ProcessStrategy tests, it does nothing when run as a test
+func TestProcessHelper(t *testing.T) {
+	mode := os.Getenv("KATE_PROCESS_HELPER")
+	if mode == "" {
+		return
+	}
+
+	type helperMessage struct {
+		Type   string `json:"type"`
+		Candle struct {
+			Index int     `json:"index"`
+			Close float64 `json:"close"`
+		} `json:"candle"`
+		Position *struct {
+			EntryCandle int `json:"entry_candle"`
+		} `json:"position"`
+		Summary struct {
+			TotalTrades int `json:"total_trades"`
+		} `json:"summary"`
+	}
+	if mode == "deaf" {
+		//answers every candle without reading the messages until the input pipe is full
+		for {
+			fmt.Println("{}")
+		}
+	}
+	counts := map[string]int{}
+	lastClose := 0.0
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var message helperMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid message", err)
+			os.Exit(1)
+		}
+		counts[message.Type]++
+
+		switch message.Type {
+		case "candle":
+			reply := map[string]interface{}{}
+			closing := message.Position != nil && message.Candle.Index-message.Position.EntryCandle >= 5
+			switch {
+			case mode == "timeout":
+				time.Sleep(time.Second)
+			case mode == "error" && message.Candle.Index == 3:
+				reply["error"] = "model failed"
+			case mode == "crash":
+				fmt.Fprintln(os.Stderr, "segmentation fault")
+				os.Exit(3)
+			case mode == "invalid":
+				fmt.Println("buy")
+				continue
+			case closing:
+				reply["close"] = true
+			}
+			if (message.Position == nil || closing) && lastClose > 0 && message.Candle.Close > lastClose {
+				reply["open"] = map[string]interface{}{"direction": "long", "leverage": 10,
+					"stop": message.Candle.Close * 0.99, "target": message.Candle.Close * 1.01}
+			}
+			lastClose = message.Candle.Close
+			content, _ := json.Marshal(reply)
+			fmt.Println(string(content))
+		case "end":
+			counts["summary_trades"] = message.Summary.TotalTrades
+			content, _ := json.Marshal(counts)
+			os.WriteFile(os.Getenv("KATE_PROCESS_COUNTS"), content, 0644)
+		}
+	}
+	if mode == "exit" {
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
+
+func newHelperProcess(t *testing.T, mode string) (*ProcessStrategy, string) {
+	counts := t.TempDir() + "/counts.json"
+	strategy := NewProcessStrategy(os.Args[0], "-test.run=TestProcessHelper")
+	strategy.Env = []string{"KATE_PROCESS_HELPER=" + mode, "KATE_PROCESS_COUNTS=" + counts}
+	strategy.Timeout = 200 * time.Millisecond
+	return strategy, counts
+}
+
+func TestProcessStrategy(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD1.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy, countsPath := newHelperProcess(t, "momentum")
+	strategy.Timeout = 5 * time.Second
+	backtester := NewBacktester(strategy, data)
+	backtester.SetLookback(10)
+	stats := backtester.Run()
+	if strategy.Err() != nil {
+		t.Fatalf("Unexpected error of the process: %v", strategy.Err())
+	}
+
+	expected := NewBacktester(&holdingStrategy{candle: -1}, data)
+	expected.SetLookback(10)
+	expectedStats := expected.Run()
+	if stats.TotalTrades == 0 || stats.TotalTrades != expectedStats.TotalTrades ||
+		!isEqual(stats.NetProfit, expectedStats.NetProfit) {
+		t.Errorf("Expected the same results of the in process strategy %v trades and %v net profit, found %v and %v",
+			expectedStats.TotalTrades, expectedStats.NetProfit, stats.TotalTrades, stats.NetProfit)
+	}
+
+	content, err := os.ReadFile(countsPath)
+	if err != nil {
+		t.Fatal("could`t read the messages received by the process." + err.Error())
+	}
+	var counts map[string]int
+	json.Unmarshal(content, &counts)
+	if counts["start"] != 1 || counts["end"] != 1 || counts["candle"] != len(data.Prices)-1 ||
+		counts["closed"] != stats.TotalTrades || counts["order"] < 2*stats.TotalTrades ||
+		counts["summary_trades"] != stats.TotalTrades {
+		t.Errorf("Unexpected messages received by the process %v", counts)
+	}
+}
+
+func TestProcessStrategyErrors(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/mockdata.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	var tests = []struct {
+		mode    string
+		message string
+	}{
+		{"timeout", "didn't answer the candle 0"},
+		{"error", "reported an error on the candle 3: model failed"},
+		{"crash", "segmentation fault"},
+		{"invalid", "invalid answer to the candle 0"},
+		{"exit", "exited with error"},
+	}
+	for _, test := range tests {
+		strategy, _ := newHelperProcess(t, test.mode)
+		stats := NewBacktester(strategy, data).Run()
+		if strategy.Err() == nil || !strings.Contains(strategy.Err().Error(), test.message) {
+			t.Errorf("%v: expected a error containing '%v', found %v", test.mode, test.message, strategy.Err())
+		}
+		if test.mode != "exit" && stats.TotalTrades != 0 {
+			t.Errorf("%v: no positions should be opened after the error", test.mode)
+		}
+	}
+
+	blocked, err := PricesFromCSV("../testdata/ETHUSD1.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	strategy, _ := newHelperProcess(t, "deaf")
+	NewBacktester(strategy, blocked).Run()
+	if strategy.Err() == nil || !strings.Contains(strategy.Err().Error(), "didn't read the candle message") {
+		t.Errorf("Expected a error of the process that doesn't read its input, found %v", strategy.Err())
+	}
+
+	strategy = NewProcessStrategy("kate-missing-command")
+	NewBacktester(strategy, data).Run()
+	if strategy.Err() == nil || !strings.Contains(strategy.Err().Error(), "could not start") {
+		t.Errorf("Expected a error starting a missing command, found %v", strategy.Err())
+	}
+}
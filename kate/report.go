@@ -0,0 +1,208 @@
+package kate
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+//Report is a self-contained html page with the charts and tables of a backtest run.
+//The page has no external dependencies so it can be archived and shared offline.
+type Report struct {
+	Title string
+	Stats *Statistics
+	Data  *DataHandler //price data used on the run, required to draw the price chart
+}
+
+//NewReport creates a report for the results of a run over the provided price data
+func NewReport(title string, stats *Statistics, data *DataHandler) *Report {
+	return &Report{Title: title, Stats: stats, Data: data}
+}
+
+//SaveHTML writes the report as a html file in the provided path
+func (report *Report) SaveHTML(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := report.WriteHTML(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+//WriteHTML renders the report as a single html page
+func (report *Report) WriteHTML(w io.Writer) error {
+	if report.Stats == nil {
+		return fmt.Errorf("the report has no statistics to render")
+	}
+	return reportTemplate.Execute(w, report.page())
+}
+
+type reportMetric struct {
+	Name, Value string
+}
+
+type reportPage struct {
+	Title        string
+	Metrics      []reportMetric
+	Trades       []tradeRecord
+	PriceChart   template.HTML
+	EquityChart  template.HTML
+	Underwater   template.HTML
+	Heatmap      template.HTML
+	Distribution template.HTML
+}
+
+func (report *Report) page() reportPage {
+	stats := report.Stats
+	labels := report.timeLabels()
+
+	equity := make([]float64, len(stats.equityCurve))
+	underwater := make([]float64, len(stats.equityCurve))
+	peak := 0.0
+	for i, point := range stats.equityCurve {
+		equity[i] = point.Equity
+		peak = math.Max(peak, point.Equity)
+		if peak > 0 {
+			underwater[i] = -100 * (peak - point.Equity) / peak
+		}
+	}
+
+	returns := make([]float64, len(stats.trades))
+	for i, trade := range stats.trades {
+		returns[i] = tradeReturn(trade)
+	}
+
+	var metrics []reportMetric
+	for _, metric := range stats.metrics() {
+		metrics = append(metrics, reportMetric{Name: metric.name, Value: metric.String()})
+	}
+
+	years, months, values := report.monthlyReturnGrid()
+	return reportPage{
+		Title:        report.Title,
+		Metrics:      metrics,
+		Trades:       stats.tradeRecords(),
+		PriceChart:   report.priceChart(labels),
+		EquityChart:  lineChartSVG(equity, "#1565c0", false, nil, labels),
+		Underwater:   lineChartSVG(underwater, "#c62828", true, nil, labels),
+		Heatmap:      heatmapSVG(years, months, values),
+		Distribution: histogramSVG(returns, 30),
+	}
+}
+
+//priceChart draws the close prices with a marker for each entry and exit of the trades
+func (report *Report) priceChart(labels [2]string) template.HTML {
+	if report.Data == nil {
+		return emptyChartSVG("no price data available")
+	}
+
+	closes := make([]float64, len(report.Data.Prices))
+	for i, candle := range report.Data.Prices {
+		closes[i] = candle.Close()
+	}
+
+	var markers []chartMarker
+	for _, trade := range report.Stats.trades {
+		entryColor := "#1565c0"
+		if trade.Direction == SHORT {
+			entryColor = "#ef6c00"
+		}
+		exitColor := "#2e7d32"
+		if trade.RealizedPNL < 0 {
+			exitColor = "#c62828"
+		}
+		markers = append(markers,
+			chartMarker{index: trade.EntryCandle, value: trade.EntryPrice, color: entryColor, up: true,
+				title: fmt.Sprintf("%v entry at %v", trade.Direction, trade.EntryPrice)},
+			chartMarker{index: trade.CloseCandle, value: trade.ClosePrice, color: exitColor,
+				title: fmt.Sprintf("%v exit at %v (%.2f)", trade.ExitReason, trade.ClosePrice, trade.RealizedPNL)})
+	}
+	return lineChartSVG(closes, "#555", false, markers, labels)
+}
+
+//timeLabels are the descriptions of the first and last candles of the run
+func (report *Report) timeLabels() [2]string {
+	curve := report.Stats.equityCurve
+	if len(curve) == 0 {
+		return [2]string{}
+	}
+	first, last := curve[0], curve[len(curve)-1]
+	if first.Time.IsZero() {
+		return [2]string{"candle " + strconv.Itoa(first.Candle), "candle " + strconv.Itoa(last.Candle)}
+	}
+	return [2]string{formatTime(first.Time), formatTime(last.Time)}
+}
+
+//monthlyReturnGrid organizes the monthly returns in rows of years and columns of months, missing months are NaN
+func (report *Report) monthlyReturnGrid() ([]string, []string, [][]float64) {
+	months := make([]string, 12)
+	for i := range months {
+		months[i] = time.Month(i + 1).String()[:3]
+	}
+
+	var years []string
+	var values [][]float64
+	for _, period := range report.Stats.MonthlyReturns() {
+		year := strconv.Itoa(period.Start.Year())
+		if len(years) == 0 || years[len(years)-1] != year {
+			row := make([]float64, 12)
+			for i := range row {
+				row[i] = math.NaN()
+			}
+			years = append(years, year)
+			values = append(values, row)
+		}
+		values[len(values)-1][period.Start.Month()-1] = period.Return
+	}
+	return years, months, values
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 24px auto; max-width: 1000px; color: #222; }
+h1 { font-size: 22px; } h2 { font-size: 17px; margin-top: 32px; }
+table { border-collapse: collapse; font-size: 12px; }
+th, td { border: 1px solid #ddd; padding: 3px 8px; text-align: right; }
+th { background: #f3f3f3; } td.name { text-align: left; }
+.trades { max-height: 420px; overflow-y: auto; }
+.neg { color: #c62828; } .pos { color: #2e7d32; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<h2>Metrics</h2>
+<table>
+{{range .Metrics}}<tr><td class="name">{{.Name}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+<h2>Price</h2>
+{{.PriceChart}}
+<h2>Equity curve</h2>
+{{.EquityChart}}
+<h2>Drawdown</h2>
+{{.Underwater}}
+<h2>Monthly returns</h2>
+{{.Heatmap}}
+<h2>Distribution of trade returns</h2>
+{{.Distribution}}
+<h2>Trades</h2>
+<div class="trades">
+<table>
+<tr><th>#</th><th>Entry</th><th>Exit</th><th>Direction</th><th>Leverage</th><th>Entry price</th><th>Close price</th><th>Fee</th><th>PNL</th><th>Exit reason</th></tr>
+{{range $i, $trade := .Trades}}<tr><td>{{$i}}</td><td>{{if $trade.EntryTime}}{{$trade.EntryTime}}{{else}}{{$trade.EntryCandle}}{{end}}</td><td>{{if $trade.CloseTime}}{{$trade.CloseTime}}{{else}}{{$trade.CloseCandle}}{{end}}</td><td>{{$trade.Direction}}</td><td>{{$trade.Leverage}}</td><td>{{printf "%.4f" $trade.EntryPrice}}</td><td>{{printf "%.4f" $trade.ClosePrice}}</td><td>{{printf "%.4f" $trade.FeePaid}}</td><td class="{{if lt $trade.RealizedPNL 0.0}}neg{{else}}pos{{end}}">{{printf "%.4f" $trade.RealizedPNL}}</td><td>{{$trade.ExitReason}}</td></tr>
+{{end}}</table>
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,41 @@
+package kate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD4.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	backtester := NewBacktester(newSimpleStrategy(), data)
+	backtester.SetFixedTradeAmount(10)
+	stats := backtester.Run()
+
+	var page bytes.Buffer
+	if err := NewReport("ETHUSD4", stats, data).WriteHTML(&page); err != nil {
+		t.Fatal("could`t render the report." + err.Error())
+	}
+	html := page.String()
+
+	if strings.Count(html, "<svg") != 5 {
+		t.Errorf("The report should contain 5 charts but %v were found", strings.Count(html, "<svg"))
+	}
+
+	if strings.Contains(html, "<script") || strings.Contains(html, `src="http`) || strings.Contains(html, `href="http`) {
+		t.Errorf("The report must not depend on external resources")
+	}
+
+	for _, expected := range []string{"Monthly returns", "Jul", "2020", "Net profit", "STOPLOSS"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("The report is missing the content '%v'", expected)
+		}
+	}
+
+	if rows := strings.Count(html, "</tr>"); rows < stats.TotalTrades {
+		t.Errorf("The report contains %v table rows for %v trades", rows, stats.TotalTrades)
+	}
+}
@@ -0,0 +1,57 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+//Resample aggregates the candles in a higher timeframe (5m, 1h, 1d...) aligned to multiples of the timeframe.
+//Each resampled candle closes at the end of its interval, the last candle may aggregate an incomplete interval.
+func (handler *DataHandler) Resample(timeframe time.Duration) (*DataHandler, error) {
+	if timeframe <= 0 {
+		return nil, fmt.Errorf("the timeframe must be positive, found %v", timeframe)
+	}
+	if !handler.hasTimestamps() {
+		return nil, fmt.Errorf("resampling requires price data with timestamps")
+	}
+
+	var prices []DataPoint
+	for _, candle := range handler.Prices {
+		closeTime := intervalEnd(candle.Time(), timeframe)
+		last := len(prices) - 1
+		if last >= 0 && prices[last].timestamp.Equal(closeTime) {
+			prices[last].high = math.Max(prices[last].high, candle.high)
+			prices[last].low = math.Min(prices[last].low, candle.low)
+			prices[last].close = candle.close
+			prices[last].volume += candle.volume
+			continue
+		}
+		if last >= 0 && closeTime.Before(prices[last].timestamp) {
+			return nil, fmt.Errorf("the candles must be sorted by time, found %v after %v", candle.Time(), prices[last].timestamp)
+		}
+		candle.timestamp = closeTime
+		prices = append(prices, candle)
+	}
+	return newDataHandler(prices), nil
+}
+
+//hasTimestamps checks if every candle has the time when it was closed
+func (handler *DataHandler) hasTimestamps() bool {
+	for _, candle := range handler.Prices {
+		if candle.Time().IsZero() {
+			return false
+		}
+	}
+	return len(handler.Prices) > 0
+}
+
+//intervalEnd is the end of the interval of the timeframe containing the close time,
+//a close time exactly on a boundary belongs to the interval ending on it
+func intervalEnd(closeTime time.Time, timeframe time.Duration) time.Time {
+	end := closeTime.Truncate(timeframe)
+	if end.Before(closeTime) {
+		end = end.Add(timeframe)
+	}
+	return end
+}
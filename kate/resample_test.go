@@ -0,0 +1,113 @@
+package kate
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	for _, timeframe := range []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour} {
+		resampled, err := data.Resample(timeframe)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		volume, next := 0.0, 0
+		for i, candle := range resampled.Prices {
+			if candle.Time().Truncate(timeframe) != candle.Time() {
+				t.Fatalf("%v: the candle %v closes at %v, outside the timeframe boundaries", timeframe, i, candle.Time())
+			}
+
+			high, low := math.Inf(-1), math.Inf(1)
+			first := next
+			for ; next < len(data.Prices) && !data.Prices[next].Time().After(candle.Time()); next++ {
+				high = math.Max(high, data.Prices[next].High())
+				low = math.Min(low, data.Prices[next].Low())
+			}
+			if candle.Open() != data.Prices[first].Open() || candle.Close() != data.Prices[next-1].Close() ||
+				candle.High() != high || candle.Low() != low {
+				t.Fatalf("%v: the candle %v doesn't aggregate the candles %v to %v", timeframe, i, first, next-1)
+			}
+			volume += candle.Volume()
+		}
+
+		total := 0.0
+		for _, candle := range data.Prices {
+			total += candle.Volume()
+		}
+		if next != len(data.Prices) || !isEqual(volume, total) {
+			t.Errorf("%v: the resampled candles should contain every candle and the total volume", timeframe)
+		}
+	}
+
+	if _, err := (&DataHandler{Prices: []DataPoint{{close: 1}}}).Resample(time.Hour); err == nil {
+		t.Errorf("Resampling data without timestamps should fail")
+	}
+}
+
+//timeframeStrategy records the higher timeframe candles received and checks that none is from the future
+type timeframeStrategy struct {
+	simpleStrategy
+	t         *testing.T
+	delivered map[string][]DataPoint
+}
+
+func (strategy *timeframeStrategy) PreProcessTimeframe(timeframe string, closedCandle DataPoint) {
+	strategy.delivered[timeframe] = append(strategy.delivered[timeframe], closedCandle)
+}
+
+func (strategy *timeframeStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	for timeframe, candles := range strategy.delivered {
+		if last := candles[len(candles)-1]; last.Time().After(latestPrice.Time()) {
+			strategy.t.Fatalf("The %v candle closed at %v was delivered at %v", timeframe, last.Time(), latestPrice.Time())
+		}
+	}
+	strategy.simpleStrategy.PreProcessIndicators(latestPrice)
+}
+
+func TestMultipleTimeframes(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	hourly, _ := data.Resample(time.Hour)
+	fiveMinutes, _ := data.Resample(5 * time.Minute)
+
+	strategy := &timeframeStrategy{t: t, delivered: map[string][]DataPoint{}}
+	backtester := NewBacktester(strategy, data)
+	if err := backtester.AddTimeframe("1h", hourly); err != nil {
+		t.Fatal(err)
+	}
+	if err := backtester.AddTimeframe("5m", fiveMinutes); err != nil {
+		t.Fatal(err)
+	}
+	if err := backtester.AddTimeframe("1h", hourly); err == nil {
+		t.Errorf("A timeframe should not be added twice")
+	}
+	backtester.Run()
+
+	//the last candle of the data is never processed so the candles closed after the previous one are pending
+	lastProcessed := data.Prices[len(data.Prices)-2].Time()
+	for name, feed := range map[string]*DataHandler{"1h": hourly, "5m": fiveMinutes} {
+		closed := 0
+		for _, candle := range feed.Prices {
+			if !candle.Time().After(lastProcessed) {
+				closed++
+			}
+		}
+		if len(strategy.delivered[name]) != closed {
+			t.Errorf("Expected %v closed %v candles to be delivered, found %v", closed, name, len(strategy.delivered[name]))
+		}
+	}
+
+	withoutTime := NewBacktester(newSimpleStrategy(), &DataHandler{Prices: []DataPoint{{close: 1}}})
+	if err := withoutTime.AddTimeframe("1h", hourly); err == nil {
+		t.Errorf("Timeframes require price data with timestamps")
+	}
+}
@@ -0,0 +1,179 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+//RiskManager reviews every position requested by the strategy before it reaches the exchange,
+//the position can be approved, resized or vetoed
+type RiskManager interface {
+	Review(request RiskRequest) RiskDecision
+}
+
+//RiskRequest is a position requested by the strategy together with the state of the account
+type RiskRequest struct {
+	Candle            int
+	Time              time.Time
+	Direction         Direction
+	Leverage          uint
+	Amount            float64 //margin of the position
+	Price             float64
+	Balance           float64
+	Equity            float64
+	DayStartEquity    float64 //equity on the first candle of the UTC day, the initial balance without timestamps
+	MaxDrawdown       float64 //largest drawdown percentage of the equity reached in the run
+	TradesToday       int     //positions opened on the UTC day
+	ConsecutiveLosses int     //losing positions closed in a row since the last win
+	LastLossCandle    int     //candle the last losing position was closed, -1 when no position was lost
+}
+
+//RiskDecision is the outcome of the review of a position, the interventions describe every change made
+type RiskDecision struct {
+	Vetoed        bool
+	Leverage      uint
+	Amount        float64
+	Interventions []RiskIntervention
+}
+
+//RiskAction denotes how a risk manager intervened on a position
+type RiskAction int
+
+const (
+	//RiskVeto denotes a position that was not sent to the exchange
+	RiskVeto RiskAction = iota
+	//RiskResize denotes a position sent with a smaller leverage or amount
+	RiskResize
+)
+
+//RiskIntervention is a record of a position vetoed or resized by the risk manager
+type RiskIntervention struct {
+	Candle int //index of the candle when the position was requested
+	Time   time.Time
+	Rule   string
+	Action RiskAction
+	Detail string
+}
+
+//RiskLimits is a risk manager with the usual account level guardrails, limits with the zero value are disabled
+type RiskLimits struct {
+	MaxLeverage            uint    //higher leverages are reduced to the maximum
+	MaxExposurePercentage  float64 //maximum position size (margin * leverage) in percentage of the equity
+	MaxDailyLossPercentage float64 //no positions are opened for the rest of the UTC day after losing this much
+	MaxDrawdownPercentage  float64 //no positions are opened for the rest of the run after this drawdown (kill-switch)
+	MaxConsecutiveLosses   int     //losses in a row that start a cooldown
+	CooldownCandles        int     //candles without new positions after the last of the consecutive losses
+	MaxTradesPerDay        int
+}
+
+//riskState is the state of the account tracked during a run for the risk manager
+type riskState struct {
+	peakEquity     float64
+	maxDrawdown    float64
+	dayStartEquity float64
+	tradesToday    int
+	interventions  []RiskIntervention
+}
+
+//Review vetoes the position when a loss limit or the trades per day are reached, otherwise reduces the leverage
+//and amount to the limits
+func (limits RiskLimits) Review(request RiskRequest) RiskDecision {
+	decision := RiskDecision{Leverage: request.Leverage, Amount: request.Amount}
+	veto := func(rule, detail string, args ...interface{}) RiskDecision {
+		decision.Vetoed = true
+		decision.Interventions = []RiskIntervention{{Rule: rule, Action: RiskVeto, Detail: fmt.Sprintf(detail, args...)}}
+		return decision
+	}
+
+	if limits.MaxDrawdownPercentage > 0 && request.MaxDrawdown >= limits.MaxDrawdownPercentage {
+		return veto("max drawdown", "drawdown of %.2f%% reached the limit of %.2f%%", request.MaxDrawdown,
+			limits.MaxDrawdownPercentage)
+	}
+	if limits.MaxDailyLossPercentage > 0 && request.DayStartEquity > 0 {
+		loss := 100 * (request.DayStartEquity - request.Equity) / request.DayStartEquity
+		if loss >= limits.MaxDailyLossPercentage {
+			return veto("max daily loss", "daily loss of %.2f%% reached the limit of %.2f%%", loss,
+				limits.MaxDailyLossPercentage)
+		}
+	}
+	if limits.MaxConsecutiveLosses > 0 && request.ConsecutiveLosses >= limits.MaxConsecutiveLosses &&
+		request.Candle-request.LastLossCandle <= limits.CooldownCandles {
+		return veto("cooldown", "%v consecutive losses, cooldown until candle %v", request.ConsecutiveLosses,
+			request.LastLossCandle+limits.CooldownCandles+1)
+	}
+	if limits.MaxTradesPerDay > 0 && request.TradesToday >= limits.MaxTradesPerDay {
+		return veto("max trades per day", "%v positions were already opened today", request.TradesToday)
+	}
+
+	if limits.MaxLeverage > 0 && decision.Leverage > limits.MaxLeverage {
+		decision.Interventions = append(decision.Interventions, RiskIntervention{Rule: "max leverage",
+			Action: RiskResize, Detail: fmt.Sprintf("leverage reduced from %v to %v", decision.Leverage, limits.MaxLeverage)})
+		decision.Leverage = limits.MaxLeverage
+	}
+	if limits.MaxExposurePercentage > 0 {
+		leverage := math.Max(1, float64(decision.Leverage))
+		maxAmount := request.Equity * limits.MaxExposurePercentage / 100 / leverage
+		if decision.Amount > maxAmount {
+			decision.Interventions = append(decision.Interventions, RiskIntervention{Rule: "max exposure",
+				Action: RiskResize, Detail: fmt.Sprintf("amount reduced from %.2f to %.2f", decision.Amount, maxAmount)})
+			decision.Amount = maxAmount
+		}
+	}
+	return decision
+}
+
+//String returns the name of the action
+func (action RiskAction) String() string {
+	if action == RiskResize {
+		return "RESIZE"
+	}
+	return "VETO"
+}
+
+//reviewRisk asks the risk manager to review the position recording its interventions,
+//false is returned when the position was vetoed
+func (sim *simulation) reviewRisk(event *OpenPositionEvt, leverage uint, amount float64) (uint, float64, bool) {
+	handler := sim.exchangeHandler
+	request := RiskRequest{
+		Candle:         handler.currentCandle,
+		Time:           handler.currentTime,
+		Direction:      event.Direction,
+		Leverage:       leverage,
+		Amount:         handler.amountToTrade(amount),
+		Price:          handler.currentPrice,
+		Balance:        handler.balance,
+		Equity:         handler.equity(),
+		DayStartEquity: sim.risk.dayStartEquity,
+		MaxDrawdown:    sim.risk.maxDrawdown,
+		TradesToday:    sim.risk.tradesToday,
+		LastLossCandle: -1,
+	}
+	for i := len(handler.tradeHistory) - 1; i >= 0 && handler.tradeHistory[i].RealizedPNL < 0; i-- {
+		if request.ConsecutiveLosses == 0 {
+			request.LastLossCandle = handler.tradeHistory[i].CloseCandle
+		}
+		request.ConsecutiveLosses++
+	}
+
+	decision := sim.riskManager.Review(request)
+	for _, intervention := range decision.Interventions {
+		intervention.Candle, intervention.Time = request.Candle, request.Time
+		sim.risk.interventions = append(sim.risk.interventions, intervention)
+	}
+	return decision.Leverage, decision.Amount, !decision.Vetoed
+}
+
+//updateEquity tracks the peak and drawdown of the equity
+func (state *riskState) updateEquity(equity float64) {
+	state.peakEquity = math.Max(state.peakEquity, equity)
+	if state.peakEquity > 0 {
+		state.maxDrawdown = math.Max(state.maxDrawdown, 100*(state.peakEquity-equity)/state.peakEquity)
+	}
+}
+
+//startDay resets the daily limits on the first candle of a UTC day
+func (state *riskState) startDay(equity float64) {
+	state.dayStartEquity = equity
+	state.tradesToday = 0
+}
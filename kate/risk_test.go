@@ -0,0 +1,103 @@
+package kate
+
+import "testing"
+
+func TestRiskLimitsReview(t *testing.T) {
+	base := RiskRequest{Candle: 50, Leverage: 10, Amount: 100, Balance: 1000, Equity: 1000, DayStartEquity: 1000,
+		LastLossCandle: -1}
+	limits := RiskLimits{MaxLeverage: 5, MaxExposurePercentage: 200, MaxDailyLossPercentage: 3,
+		MaxDrawdownPercentage: 10, MaxConsecutiveLosses: 3, CooldownCandles: 20, MaxTradesPerDay: 4}
+
+	var tests = []struct {
+		name     string
+		change   func(request *RiskRequest)
+		vetoed   bool
+		rules    []string
+		leverage uint
+		amount   float64
+	}{
+		{"within limits", func(request *RiskRequest) { request.Leverage = 2 }, false, nil, 2, 100},
+		{"leverage reduced", func(request *RiskRequest) {}, false, []string{"max leverage"}, 5, 100},
+		{"exposure reduced", func(request *RiskRequest) { request.Amount = 500 }, false,
+			[]string{"max leverage", "max exposure"}, 5, 400},
+		{"kill-switch", func(request *RiskRequest) { request.MaxDrawdown = 12 }, true, []string{"max drawdown"}, 10, 100},
+		{"daily loss", func(request *RiskRequest) { request.Equity = 960 }, true, []string{"max daily loss"}, 10, 100},
+		{"cooldown", func(request *RiskRequest) {
+			request.ConsecutiveLosses, request.LastLossCandle = 3, 40
+		}, true, []string{"cooldown"}, 10, 100},
+		{"cooldown finished", func(request *RiskRequest) {
+			request.ConsecutiveLosses, request.LastLossCandle, request.Leverage = 3, 29, 1
+		}, false, nil, 1, 100},
+		{"trades per day", func(request *RiskRequest) { request.TradesToday = 4 }, true,
+			[]string{"max trades per day"}, 10, 100},
+	}
+
+	for _, test := range tests {
+		request := base
+		test.change(&request)
+		decision := limits.Review(request)
+		if decision.Vetoed != test.vetoed || decision.Leverage != test.leverage || !isEqual(decision.Amount, test.amount) {
+			t.Errorf("%v: unexpected decision %+v", test.name, decision)
+		}
+		if len(decision.Interventions) != len(test.rules) {
+			t.Fatalf("%v: expected the interventions %v, found %+v", test.name, test.rules, decision.Interventions)
+		}
+		for i, intervention := range decision.Interventions {
+			if intervention.Rule != test.rules[i] {
+				t.Errorf("%v: expected the rule %v, found %v", test.name, test.rules[i], intervention.Rule)
+			}
+		}
+	}
+}
+
+func TestRiskManagerOnBacktester(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	backtester := NewBacktester(newSimpleStrategy(), data)
+	backtester.SetBalance(1000)
+	backtester.SetRiskManager(RiskLimits{MaxLeverage: 5, MaxTradesPerDay: 10})
+	stats := backtester.Run()
+
+	tradesPerDay := map[string]int{}
+	for _, trade := range stats.Trades() {
+		if trade.Leverage != 5 {
+			t.Fatalf("Expected the leverage to be reduced to 5, found %v", trade.Leverage)
+		}
+		tradesPerDay[trade.EntryTime.UTC().Format("2006-01-02")]++
+	}
+	for day, trades := range tradesPerDay {
+		if trades > 10 {
+			t.Errorf("Expected at most 10 trades per day, found %v on %v", trades, day)
+		}
+	}
+
+	resized, vetoed := 0, 0
+	for _, intervention := range stats.RiskInterventions() {
+		if intervention.Time.IsZero() {
+			t.Fatalf("The intervention should have the time of the candle %+v", intervention)
+		}
+		if intervention.Action == RiskResize {
+			resized++
+		} else {
+			vetoed++
+		}
+	}
+	if resized < stats.TotalTrades || vetoed == 0 {
+		t.Errorf("Expected at least %v positions resized and some vetoed, found %v and %v", stats.TotalTrades, resized, vetoed)
+	}
+
+	backtester.SetRiskManager(RiskLimits{MaxDrawdownPercentage: 2})
+	stats = backtester.Run()
+	interventions := stats.RiskInterventions()
+	if len(interventions) == 0 || interventions[0].Rule != "max drawdown" {
+		t.Fatalf("Expected the kill-switch to veto the positions, found %+v", interventions)
+	}
+	for _, trade := range stats.Trades() {
+		if trade.EntryCandle > interventions[0].Candle {
+			t.Fatalf("No position should be opened after the kill-switch, found one at candle %v", trade.EntryCandle)
+		}
+	}
+}
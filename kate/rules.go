@@ -0,0 +1,482 @@
+package kate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/victorl2/kate-backtester/indicators"
+)
+
+//RuleConfig is a strategy defined declaratively, usually loaded from json with LoadRules. Positions are opened when
+//the entry condition of a side is met and closed with a market order when its exit condition is met, besides the
+//stoploss and takeprofit set when the position is opened.
+type RuleConfig struct {
+	Name     string    `json:"name"`
+	Leverage uint      `json:"leverage"`
+	Long     *RuleSide `json:"long"`
+	Short    *RuleSide `json:"short"`
+	Stop     *ExitRule `json:"stop"`
+	Target   *ExitRule `json:"target"`
+}
+
+//RuleSide are the conditions to open and close positions in a direction, the exit is optional
+type RuleSide struct {
+	Entry *Condition `json:"entry"`
+	Exit  *Condition `json:"exit"`
+}
+
+//Condition is either a group of conditions combined with all (AND) or any (OR) or a comparison of two operands.
+//The operands are numbers or expressions like close, sma(20), rsi(14) or bollinger_upper(20, 2) and the operators
+//are >, >=, <, <=, crosses_above and crosses_below.
+type Condition struct {
+	All   []Condition `json:"all"`
+	Any   []Condition `json:"any"`
+	Left  jsonValue   `json:"left"`
+	Op    string      `json:"op"`
+	Right jsonValue   `json:"right"`
+}
+
+//ExitRule is the distance of the stoploss or takeprofit from the entry price, in percentage of the price, in
+//multiples of the ATR or, only for the takeprofit, in multiples of the stoploss distance
+type ExitRule struct {
+	Percentage float64 `json:"percentage"`
+	ATR        int     `json:"atr"` //period of the ATR
+	Multiple   float64 `json:"multiple"`
+	RiskReward float64 `json:"risk_reward"`
+}
+
+//RuleStrategy is the Strategy compiled from a RuleConfig, the indicators are reset at the start of every run
+type RuleStrategy struct {
+	config RuleConfig
+	engine *ruleEngine
+}
+
+//ruleEngine is the state of the compiled rules during a run
+type ruleEngine struct {
+	indicators  map[string]indicators.Indicator
+	comparisons []*comparison
+	conditions  [4]func() bool //long entry, long exit, short entry and short exit
+	stopATR     indicators.Indicator
+	targetATR   indicators.Indicator
+	latest      DataPoint
+}
+
+//comparison is a compiled comparison keeping the previous values of the operands for the crossovers
+type comparison struct {
+	left, right         operand
+	op                  string
+	previous            [2]float64
+	hasPrevious, result bool
+}
+
+//operand returns the value of a number, price or indicator and if it is ready
+type operand func() (float64, bool)
+
+//ruleIndicator is a indicator available on the expressions, indicators with the same family and arguments are
+//shared by the expressions
+type ruleIndicator struct {
+	family  string
+	args    int
+	periods int //leading arguments that are periods (candles), the others are multipliers
+	build   func(args []float64) indicators.Indicator
+	value   func(indicator indicators.Indicator) float64
+}
+
+var expressionPattern = regexp.MustCompile(`^([a-z_]+)\s*(?:\((.*)\))?$`)
+
+var ruleIndicators = map[string]ruleIndicator{
+	"sma": {"sma", 1, 1, func(args []float64) indicators.Indicator { return indicators.NewSMA(int(args[0])) },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.SMA).Value() }},
+	"ema": {"ema", 1, 1, func(args []float64) indicators.Indicator { return indicators.NewEMA(int(args[0])) },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.EMA).Value() }},
+	"wma": {"wma", 1, 1, func(args []float64) indicators.Indicator { return indicators.NewWMA(int(args[0])) },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.WMA).Value() }},
+	"rsi": {"rsi", 1, 1, func(args []float64) indicators.Indicator { return indicators.NewRSI(int(args[0])) },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.RSI).Value() }},
+	"atr": {"atr", 1, 1, newRuleATR,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.ATR).Value() }},
+	"adx": {"adx", 1, 1, newRuleADX,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.ADX).Value() }},
+	"plus_di": {"adx", 1, 1, newRuleADX,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.ADX).PlusDI() }},
+	"minus_di": {"adx", 1, 1, newRuleADX,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.ADX).MinusDI() }},
+	"macd": {"macd", 3, 3, newRuleMACD,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.MACD).MACD() }},
+	"macd_signal": {"macd", 3, 3, newRuleMACD,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.MACD).Signal() }},
+	"macd_histogram": {"macd", 3, 3, newRuleMACD,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.MACD).Histogram() }},
+	"stochastic_k": {"stochastic", 2, 2, newRuleStochastic,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Stochastic).K() }},
+	"stochastic_d": {"stochastic", 2, 2, newRuleStochastic,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Stochastic).D() }},
+	"bollinger_upper": {"bollinger", 2, 1, newRuleBollinger,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Bollinger).Upper() }},
+	"bollinger_middle": {"bollinger", 2, 1, newRuleBollinger,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Bollinger).Middle() }},
+	"bollinger_lower": {"bollinger", 2, 1, newRuleBollinger,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Bollinger).Lower() }},
+	"keltner_upper": {"keltner", 3, 2, newRuleKeltner,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Keltner).Upper() }},
+	"keltner_middle": {"keltner", 3, 2, newRuleKeltner,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Keltner).Middle() }},
+	"keltner_lower": {"keltner", 3, 2, newRuleKeltner,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Keltner).Lower() }},
+	"donchian_upper": {"donchian", 1, 1, newRuleDonchian,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Donchian).Upper() }},
+	"donchian_middle": {"donchian", 1, 1, newRuleDonchian,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Donchian).Middle() }},
+	"donchian_lower": {"donchian", 1, 1, newRuleDonchian,
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.Donchian).Lower() }},
+	"supertrend": {"supertrend", 2, 1,
+		func(args []float64) indicators.Indicator { return indicators.NewSuperTrend(int(args[0]), args[1]) },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.SuperTrend).Value() }},
+	"obv": {"obv", 0, 0, func(args []float64) indicators.Indicator { return indicators.NewOBV() },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.OBV).Value() }},
+	"vwap": {"vwap", 0, 0, func(args []float64) indicators.Indicator { return indicators.NewVWAP() },
+		func(indicator indicators.Indicator) float64 { return indicator.(*indicators.VWAP).Value() }},
+}
+
+//LoadRules reads the json file of a rule based strategy and validates it
+func LoadRules(path string) (*RuleConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(content)
+}
+
+//ParseRules decodes the json of a rule based strategy and validates it
+func ParseRules(content []byte) (*RuleConfig, error) {
+	var config RuleConfig
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid rules: %v", err)
+	}
+	if _, err := config.Compile(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+//Compile builds the strategy of the rules, a error is returned when a condition or expression is invalid
+func (config RuleConfig) Compile() (*RuleStrategy, error) {
+	if config.Long == nil && config.Short == nil {
+		return nil, fmt.Errorf("the rules need the conditions of a long or short side")
+	}
+	if config.Leverage == 0 {
+		config.Leverage = 1
+	}
+
+	engine := &ruleEngine{indicators: map[string]indicators.Indicator{}}
+	for i, side := range []*RuleSide{config.Long, config.Short} {
+		name := [2]string{"long", "short"}[i]
+		engine.conditions[2*i], engine.conditions[2*i+1] = never, never
+		if side == nil {
+			continue
+		}
+		if side.Entry == nil {
+			return nil, fmt.Errorf("the %v side needs a entry condition", name)
+		}
+
+		var err error
+		if engine.conditions[2*i], err = engine.compileCondition(*side.Entry); err != nil {
+			return nil, fmt.Errorf("invalid %v entry: %v", name, err)
+		}
+		if side.Exit != nil {
+			if engine.conditions[2*i+1], err = engine.compileCondition(*side.Exit); err != nil {
+				return nil, fmt.Errorf("invalid %v exit: %v", name, err)
+			}
+		}
+	}
+
+	var err error
+	if engine.stopATR, err = engine.compileExit("stop", config.Stop); err != nil {
+		return nil, err
+	}
+	if engine.targetATR, err = engine.compileExit("target", config.Target); err != nil {
+		return nil, err
+	}
+	if config.Target != nil && config.Target.RiskReward > 0 && config.Stop == nil {
+		return nil, fmt.Errorf("a target with risk_reward requires a stop")
+	}
+	return &RuleStrategy{config: config, engine: engine}, nil
+}
+
+//OnStart resets the indicators so every run starts from the same state
+func (strategy *RuleStrategy) OnStart(config RunConfig) {
+	fresh, _ := strategy.config.Compile()
+	strategy.engine = fresh.engine
+}
+
+//PreProcessIndicators updates the indicators and evaluates every comparison
+func (strategy *RuleStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	engine := strategy.engine
+	engine.latest = latestPrice
+	for _, indicator := range engine.indicators {
+		indicator.Update(latestPrice)
+	}
+	for _, comparison := range engine.comparisons {
+		comparison.update()
+	}
+}
+
+//OpenNewPosition opens a long position when the long entry is met, or else a short position when the short
+//entry is met
+func (strategy *RuleStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	engine := strategy.engine
+	for _, atr := range []indicators.Indicator{engine.stopATR, engine.targetATR} {
+		if atr != nil && !atr.Ready() {
+			return nil
+		}
+	}
+
+	direction := LONG
+	if !engine.conditions[0]() {
+		if !engine.conditions[2]() {
+			return nil
+		}
+		direction = SHORT
+	}
+
+	price := latestPrice.Close()
+	event := &OpenPositionEvt{Direction: direction, Leverage: strategy.config.Leverage}
+	stop := distance(strategy.config.Stop, engine.stopATR, price, 0)
+	target := distance(strategy.config.Target, engine.targetATR, price, stop)
+	sign := 1.0
+	if direction == SHORT {
+		sign = -1
+	}
+	if stop > 0 {
+		event.Stoploss = price - sign*stop
+	}
+	if target > 0 {
+		event.TakeProfit = price + sign*target
+	}
+	return event
+}
+
+//ClosePosition closes the position when the exit condition of its side is met
+func (strategy *RuleStrategy) ClosePosition(openPosition Position) bool {
+	if openPosition.Direction == SHORT {
+		return strategy.engine.conditions[3]()
+	}
+	return strategy.engine.conditions[1]()
+}
+
+//SetStoploss the stoploss is only set when the position is opened
+func (strategy *RuleStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	return nil
+}
+
+//SetTakeProfit the takeprofit is only set when the position is opened
+func (strategy *RuleStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+//distance is the distance of the stoploss or takeprofit from the price, zero when there is none
+func distance(rule *ExitRule, atr indicators.Indicator, price, stop float64) float64 {
+	switch {
+	case rule == nil:
+		return 0
+	case rule.Percentage > 0:
+		return price * rule.Percentage / 100
+	case atr != nil:
+		return rule.Multiple * atr.(*indicators.ATR).Value()
+	}
+	return rule.RiskReward * stop
+}
+
+func (engine *ruleEngine) compileCondition(condition Condition) (func() bool, error) {
+	groups := 0
+	for _, group := range [][]Condition{condition.All, condition.Any} {
+		if len(group) > 0 {
+			groups++
+		}
+	}
+	if groups > 1 || (groups == 1 && condition.Op != "") {
+		return nil, fmt.Errorf("a condition must be either all, any or a comparison")
+	}
+
+	if groups == 1 {
+		all := len(condition.All) > 0
+		group := condition.Any
+		if all {
+			group = condition.All
+		}
+		compiled := make([]func() bool, len(group))
+		for i := range group {
+			var err error
+			if compiled[i], err = engine.compileCondition(group[i]); err != nil {
+				return nil, err
+			}
+		}
+		return func() bool {
+			for _, condition := range compiled {
+				if condition() != all {
+					return !all
+				}
+			}
+			return all
+		}, nil
+	}
+
+	switch condition.Op {
+	case ">", ">=", "<", "<=", "crosses_above", "crosses_below":
+	default:
+		return nil, fmt.Errorf("invalid operator '%v', expected >, >=, <, <=, crosses_above or crosses_below", condition.Op)
+	}
+	left, err := engine.compileOperand(string(condition.Left))
+	if err != nil {
+		return nil, err
+	}
+	right, err := engine.compileOperand(string(condition.Right))
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &comparison{left: left, right: right, op: condition.Op}
+	engine.comparisons = append(engine.comparisons, compiled)
+	return func() bool { return compiled.result }, nil
+}
+
+//compileOperand parses a number, a price (open, high, low, close, volume) or a indicator expression
+func (engine *ruleEngine) compileOperand(expression string) (operand, error) {
+	expression = strings.ToLower(strings.TrimSpace(expression))
+	if value, err := strconv.ParseFloat(expression, 64); err == nil {
+		return func() (float64, bool) { return value, true }, nil
+	}
+
+	match := expressionPattern.FindStringSubmatch(expression)
+	if match == nil {
+		return nil, fmt.Errorf("invalid expression '%v'", expression)
+	}
+	name := match[1]
+	var args []float64
+	for _, arg := range strings.Split(match[2], ",") {
+		if arg = strings.TrimSpace(arg); arg == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument '%v' of %v", arg, name)
+		}
+		args = append(args, value)
+	}
+
+	if price, ok := map[string]func(DataPoint) float64{"open": DataPoint.Open, "high": DataPoint.High,
+		"low": DataPoint.Low, "close": DataPoint.Close, "volume": DataPoint.Volume}[name]; ok && len(args) == 0 {
+		return func() (float64, bool) { return price(engine.latest), true }, nil
+	}
+
+	definition, ok := ruleIndicators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown indicator '%v'", name)
+	}
+	if len(args) != definition.args {
+		return nil, fmt.Errorf("%v expects %v arguments, found %v", name, definition.args, len(args))
+	}
+	for i, arg := range args {
+		if i < definition.periods && (arg < 1 || arg != math.Trunc(arg)) {
+			return nil, fmt.Errorf("the period %v of %v must be a positive integer", arg, name)
+		}
+		if arg <= 0 {
+			return nil, fmt.Errorf("the multiplier %v of %v must be positive", arg, name)
+		}
+	}
+	indicator := engine.indicator(definition, args)
+	return func() (float64, bool) { return definition.value(indicator), indicator.Ready() }, nil
+}
+
+//compileExit validates the stop or target and registers the ATR it uses
+func (engine *ruleEngine) compileExit(name string, rule *ExitRule) (indicators.Indicator, error) {
+	if rule == nil {
+		return nil, nil
+	}
+	switch {
+	case rule.Percentage > 0:
+		return nil, nil
+	case rule.ATR > 0 && rule.Multiple > 0:
+		return engine.indicator(ruleIndicators["atr"], []float64{float64(rule.ATR)}), nil
+	case rule.RiskReward > 0 && name == "target":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("the %v needs a percentage or a atr period and multiple", name)
+}
+
+//indicator returns the indicator of the family with the arguments, creating it on the first use
+func (engine *ruleEngine) indicator(definition ruleIndicator, args []float64) indicators.Indicator {
+	key := fmt.Sprint(definition.family, args)
+	if indicator, ok := engine.indicators[key]; ok {
+		return indicator
+	}
+	indicator := definition.build(args)
+	engine.indicators[key] = indicator
+	return indicator
+}
+
+//update evaluates the comparison with the latest values of the operands
+func (compiled *comparison) update() {
+	left, leftReady := compiled.left()
+	right, rightReady := compiled.right()
+	if !leftReady || !rightReady {
+		compiled.result, compiled.hasPrevious = false, false
+		return
+	}
+
+	switch compiled.op {
+	case ">":
+		compiled.result = left > right
+	case ">=":
+		compiled.result = left >= right
+	case "<":
+		compiled.result = left < right
+	case "<=":
+		compiled.result = left <= right
+	case "crosses_above":
+		compiled.result = compiled.hasPrevious && compiled.previous[0] <= compiled.previous[1] && left > right
+	case "crosses_below":
+		compiled.result = compiled.hasPrevious && compiled.previous[0] >= compiled.previous[1] && left < right
+	}
+	compiled.previous, compiled.hasPrevious = [2]float64{left, right}, true
+}
+
+func never() bool {
+	return false
+}
+
+func newRuleATR(args []float64) indicators.Indicator {
+	return indicators.NewATR(int(args[0]))
+}
+
+func newRuleADX(args []float64) indicators.Indicator {
+	return indicators.NewADX(int(args[0]))
+}
+
+func newRuleMACD(args []float64) indicators.Indicator {
+	return indicators.NewMACD(int(args[0]), int(args[1]), int(args[2]))
+}
+
+func newRuleStochastic(args []float64) indicators.Indicator {
+	return indicators.NewStochastic(int(args[0]), int(args[1]))
+}
+
+func newRuleBollinger(args []float64) indicators.Indicator {
+	return indicators.NewBollinger(int(args[0]), args[1])
+}
+
+func newRuleKeltner(args []float64) indicators.Indicator {
+	return indicators.NewKeltner(int(args[0]), int(args[1]), args[2])
+}
+
+func newRuleDonchian(args []float64) indicators.Indicator {
+	return indicators.NewDonchian(int(args[0]))
+}
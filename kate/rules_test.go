@@ -0,0 +1,220 @@
+package kate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorl2/kate-backtester/indicators"
+)
+
+//crossoverStrategy is the go version of the crossover rules of TestRuleStrategy
+type crossoverStrategy struct {
+	fast, slow             *indicators.SMA
+	previous               [2]float64
+	hasPrevious            bool
+	crossAbove, crossBelow bool
+}
+
+func (strategy *crossoverStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	strategy.fast.Update(latestPrice)
+	strategy.slow.Update(latestPrice)
+	if !strategy.fast.Ready() || !strategy.slow.Ready() {
+		return
+	}
+	fast, slow := strategy.fast.Value(), strategy.slow.Value()
+	strategy.crossAbove = strategy.hasPrevious && strategy.previous[0] <= strategy.previous[1] && fast > slow
+	strategy.crossBelow = strategy.hasPrevious && strategy.previous[0] >= strategy.previous[1] && fast < slow
+	strategy.previous, strategy.hasPrevious = [2]float64{fast, slow}, true
+}
+
+func (strategy *crossoverStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	price := latestPrice.Close()
+	stop := price * 1.5 / 100
+	switch {
+	case strategy.crossAbove:
+		return &OpenPositionEvt{Direction: LONG, Leverage: 5, Stoploss: price - stop, TakeProfit: price + 2*stop}
+	case strategy.crossBelow:
+		return &OpenPositionEvt{Direction: SHORT, Leverage: 5, Stoploss: price + stop, TakeProfit: price - 2*stop}
+	}
+	return nil
+}
+
+func (strategy *crossoverStrategy) ClosePosition(openPosition Position) bool {
+	if openPosition.Direction == SHORT {
+		return strategy.crossAbove
+	}
+	return strategy.crossBelow
+}
+
+func (strategy *crossoverStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	return nil
+}
+
+func (strategy *crossoverStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+func TestRuleStrategy(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	path := filepath.Join(t.TempDir(), "crossover.json")
+	rules := `{
+		"name": "sma crossover",
+		"leverage": 5,
+		"long": {
+			"entry": {"left": "sma(5)", "op": "crosses_above", "right": "SMA( 20 )"},
+			"exit": {"left": "sma(5)", "op": "crosses_below", "right": "sma(20)"}
+		},
+		"short": {
+			"entry": {"left": "sma(5)", "op": "crosses_below", "right": "sma(20)"},
+			"exit": {"left": "sma(5)", "op": "crosses_above", "right": "sma(20)"}
+		},
+		"stop": {"percentage": 1.5},
+		"target": {"risk_reward": 2}
+	}`
+	if err := os.WriteFile(path, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadRules(path)
+	if err != nil {
+		t.Fatal("could`t load the rules. " + err.Error())
+	}
+	strategy, err := config.Compile()
+	if err != nil {
+		t.Fatal("could`t compile the rules. " + err.Error())
+	}
+	if len(strategy.engine.indicators) != 2 {
+		t.Errorf("the indicators should be shared by the expressions, found %v", len(strategy.engine.indicators))
+	}
+
+	backtester := NewBacktester(strategy, data)
+	stats := backtester.Run()
+	expected := NewBacktester(&crossoverStrategy{fast: indicators.NewSMA(5), slow: indicators.NewSMA(20)}, data).Run()
+	if stats.TotalTrades == 0 || !reflect.DeepEqual(stats.Trades(), expected.Trades()) {
+		t.Fatalf("expected the trades of the go strategy %+v, found %+v", expected.Trades(), stats.Trades())
+	}
+	if again := backtester.Run(); !reflect.DeepEqual(again.Trades(), stats.Trades()) {
+		t.Errorf("the indicators should be reset on every run, found %v trades instead of %v", again.TotalTrades,
+			stats.TotalTrades)
+	}
+}
+
+func TestRuleStrategyExits(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy, err := RuleConfig{
+		Long:   &RuleSide{Entry: &Condition{Left: "close", Op: ">", Right: "sma(10)"}},
+		Short:  &RuleSide{Entry: &Condition{Left: "close", Op: "<", Right: "sma(10)"}},
+		Stop:   &ExitRule{ATR: 14, Multiple: 2},
+		Target: &ExitRule{Percentage: 3},
+	}.Compile()
+	if err != nil {
+		t.Fatal("could`t compile the rules. " + err.Error())
+	}
+
+	stats := NewBacktester(strategy, data).Run()
+	if stats.TotalTrades == 0 {
+		t.Fatal("expected trades closed by the stoploss and takeprofit")
+	}
+	for _, trade := range stats.Trades() {
+		atr := indicators.NewATR(14)
+		for _, candle := range data.Prices[:trade.EntryCandle+1] {
+			atr.Update(candle)
+		}
+		if !atr.Ready() {
+			t.Fatalf("the position on candle %v was opened before the ATR was ready", trade.EntryCandle)
+		}
+
+		price, sign := data.Prices[trade.EntryCandle].Close(), 1.0
+		if trade.Direction == SHORT {
+			sign = -1
+		}
+		if !isEqual(trade.Stoploss, price-sign*2*atr.Value()) || !isEqual(trade.TakeProfit, price+sign*price*0.03) {
+			t.Errorf("unexpected stoploss %v and takeprofit %v for the %v position opened at %v with ATR %v",
+				trade.Stoploss, trade.TakeProfit, trade.Direction, price, atr.Value())
+		}
+		if trade.ExitReason != StoplossExit && trade.ExitReason != TakeProfitExit {
+			t.Errorf("the positions without exit conditions should be closed by the stoploss or takeprofit, found %v",
+				trade.ExitReason)
+		}
+	}
+}
+
+func TestRuleConditions(t *testing.T) {
+	strategy, err := RuleConfig{
+		Long: &RuleSide{Entry: &Condition{All: []Condition{
+			{Left: "close", Op: ">", Right: "10"},
+			{Any: []Condition{{Left: "volume", Op: ">=", Right: "100"}, {Left: "high", Op: ">", Right: "20"}}},
+		}}},
+		Short: &RuleSide{Entry: &Condition{Left: "close", Op: "crosses_below", Right: "5"}},
+	}.Compile()
+	if err != nil {
+		t.Fatal("could`t compile the rules. " + err.Error())
+	}
+
+	var tests = []struct {
+		name                string
+		high, close, volume float64
+		open                bool
+		direction           Direction
+	}{
+		{"only the first condition of all", 15, 12, 50, false, LONG},
+		{"all with the first condition of any", 15, 12, 150, true, LONG},
+		{"all with the second condition of any", 25, 12, 50, true, LONG},
+		{"crossed below", 5, 4, 50, true, SHORT},
+		{"below without crossing", 5, 3, 50, false, SHORT},
+	}
+	for _, test := range tests {
+		candle := NewDataPoint(test.close, test.high, test.close, test.close, test.volume, time.Time{})
+		strategy.PreProcessIndicators(candle)
+		event := strategy.OpenNewPosition(candle)
+		if (event != nil) != test.open || (event != nil && event.Direction != test.direction) {
+			t.Errorf("%v: unexpected position %+v", test.name, event)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	entry := `"long": {"entry": {"left": "close", "op": ">", "right": "sma(10)"}}`
+	var tests = []struct {
+		name, rules, err string
+	}{
+		{"no sides", `{"name": "empty"}`, "long or short side"},
+		{"unknown field", `{` + entry + `, "size": 2}`, "unknown field"},
+		{"no entry", `{"short": {"exit": {"left": "close", "op": ">", "right": "1"}}}`, "entry condition"},
+		{"operator", `{"long": {"entry": {"left": "close", "op": "=", "right": "1"}}}`, "invalid operator"},
+		{"indicator", `{"long": {"entry": {"left": "hma(9)", "op": ">", "right": "close"}}}`, "unknown indicator"},
+		{"arguments", `{"long": {"entry": {"left": "macd(12, 26)", "op": ">", "right": "0"}}}`, "expects 3 arguments"},
+		{"expression", `{"long": {"entry": {"left": "sma(10", "op": ">", "right": "0"}}}`, "invalid expression"},
+		{"zero period", `{"long": {"entry": {"left": "sma(0)", "op": ">", "right": "0"}}}`, "positive integer"},
+		{"negative period", `{"long": {"entry": {"left": "rsi(-3)", "op": ">", "right": "0"}}}`, "positive integer"},
+		{"fractional period", `{"long": {"entry": {"left": "sma(2.5)", "op": ">", "right": "0"}}}`, "positive integer"},
+		{"multiplier", `{"long": {"entry": {"left": "bollinger_upper(20, 0)", "op": ">", "right": "0"}}}`,
+			"must be positive"},
+		{"all and any", `{"long": {"entry": {"all": [{"left": "close", "op": ">", "right": "1"}],
+			"any": [{"left": "close", "op": ">", "right": "1"}]}}}`, "either all, any or a comparison"},
+		{"stop", `{` + entry + `, "stop": {"atr": 14}}`, "the stop needs"},
+		{"risk reward", `{` + entry + `, "target": {"risk_reward": 2}}`, "requires a stop"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseRules([]byte(test.rules))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%v: expected the error '%v', found %v", test.name, test.err, err)
+		}
+	}
+	if _, err := ParseRules([]byte(`{"long": {"entry": {"left": "close", "op": ">", "right": "keltner_upper(20, 10, 1.5)"}},
+		"stop": {"atr": 14, "multiple": 1.5}}`)); err != nil {
+		t.Errorf("expected valid rules, found %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package kate
+
+import "time"
+
+//defaultHistory is the amount of candles kept by the backtester when no lookback is defined
+const defaultHistory = 100
+
+//Series is a fixed capacity history of the latest candles. The candles are accessed by how many candles ago
+//they were received: 0 is the latest candle, 1 the previous one and so on.
+type Series struct {
+	candles []DataPoint
+	start   int
+	size    int
+}
+
+//HistoryStrategy is implemented by strategies that read the price history kept by the backtester.
+//SetHistory is called at the start of every run with the series updated before each PreProcessIndicators.
+type HistoryStrategy interface {
+	SetHistory(history *Series)
+}
+
+//NewSeries creates a series that keeps the latest candles up to the capacity
+func NewSeries(capacity int) *Series {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Series{candles: make([]DataPoint, capacity)}
+}
+
+//Add inserts the latest candle removing the oldest one when the series is full
+func (series *Series) Add(candle DataPoint) {
+	if series.size == len(series.candles) {
+		series.candles[series.start] = candle
+		series.start = (series.start + 1) % len(series.candles)
+		return
+	}
+	series.candles[(series.start+series.size)%len(series.candles)] = candle
+	series.size++
+}
+
+//Len is the amount of candles in the series
+func (series *Series) Len() int {
+	return series.size
+}
+
+//Capacity is the maximum amount of candles kept
+func (series *Series) Capacity() int {
+	return len(series.candles)
+}
+
+//Full checks if the series reached its capacity
+func (series *Series) Full() bool {
+	return series.size == len(series.candles)
+}
+
+//At returns the candle received n candles ago, the zero value is returned when n is out of the series
+func (series *Series) At(n int) DataPoint {
+	if n < 0 || n >= series.size {
+		return DataPoint{}
+	}
+	return series.candles[(series.start+series.size-1-n)%len(series.candles)]
+}
+
+//Open is the open price of the candle received n candles ago
+func (series *Series) Open(n int) float64 {
+	return series.At(n).Open()
+}
+
+//High is the high price of the candle received n candles ago
+func (series *Series) High(n int) float64 {
+	return series.At(n).High()
+}
+
+//Low is the low price of the candle received n candles ago
+func (series *Series) Low(n int) float64 {
+	return series.At(n).Low()
+}
+
+//Close is the close price of the candle received n candles ago
+func (series *Series) Close(n int) float64 {
+	return series.At(n).Close()
+}
+
+//Volume is the volume of the candle received n candles ago
+func (series *Series) Volume(n int) float64 {
+	return series.At(n).Volume()
+}
+
+//Time is the close time of the candle received n candles ago
+func (series *Series) Time(n int) time.Time {
+	return series.At(n).Time()
+}
+
+//Closes returns the close prices from the oldest to the latest candle
+func (series *Series) Closes() []float64 {
+	return series.values(DataPoint.Close)
+}
+
+//Highs returns the high prices from the oldest to the latest candle
+func (series *Series) Highs() []float64 {
+	return series.values(DataPoint.High)
+}
+
+//Lows returns the low prices from the oldest to the latest candle
+func (series *Series) Lows() []float64 {
+	return series.values(DataPoint.Low)
+}
+
+//Volumes returns the volumes from the oldest to the latest candle
+func (series *Series) Volumes() []float64 {
+	return series.values(DataPoint.Volume)
+}
+
+func (series *Series) values(field func(DataPoint) float64) []float64 {
+	values := make([]float64, series.size)
+	for i := range values {
+		values[i] = field(series.At(series.size - 1 - i))
+	}
+	return values
+}
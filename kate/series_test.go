@@ -0,0 +1,79 @@
+package kate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeries(t *testing.T) {
+	series := NewSeries(3)
+	for _, close := range []float64{1, 2} {
+		series.Add(DataPoint{close: close, high: close + 1})
+	}
+	if series.Full() || series.Len() != 2 || series.Close(0) != 2 || series.Close(1) != 1 || series.Close(2) != 0 {
+		t.Errorf("Unexpected series before being full: %v", series.Closes())
+	}
+
+	for _, close := range []float64{3, 4, 5} {
+		series.Add(DataPoint{close: close, high: close + 1})
+	}
+	if !series.Full() || series.Capacity() != 3 || series.Close(0) != 5 || series.High(2) != 4 || series.Close(3) != 0 {
+		t.Errorf("Unexpected series after being full: %v", series.Closes())
+	}
+	if !reflect.DeepEqual(series.Closes(), []float64{3, 4, 5}) || !reflect.DeepEqual(series.Highs(), []float64{4, 5, 6}) {
+		t.Errorf("Expected the values from the oldest to the latest candle, found %v", series.Closes())
+	}
+}
+
+//historyStrategy opens a long position when the close is above the close of the lookback candles ago
+type historyStrategy struct {
+	simpleStrategy
+	t           *testing.T
+	history     *Series
+	candles     int
+	firstSignal int
+}
+
+func (strategy *historyStrategy) SetHistory(history *Series) {
+	strategy.history = history
+	strategy.candles, strategy.firstSignal = 0, -1
+}
+
+func (strategy *historyStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	if strategy.history.At(0) != latestPrice {
+		strategy.t.Fatalf("The history should be updated before PreProcessIndicators")
+	}
+	strategy.candles++
+}
+
+func (strategy *historyStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	if strategy.firstSignal < 0 {
+		strategy.firstSignal = strategy.candles
+	}
+	if strategy.history.Close(0) > strategy.history.Close(strategy.history.Len()-1) {
+		return &OpenPositionEvt{Direction: LONG, Leverage: 10}
+	}
+	return nil
+}
+
+func TestBacktesterLookback(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	strategy := &historyStrategy{t: t}
+	backtester := NewBacktester(strategy, data)
+	if backtester.Run(); strategy.firstSignal != 1 || strategy.history.Capacity() != defaultHistory {
+		t.Errorf("Without a lookback the strategy should trade from the first candle, found %v", strategy.firstSignal)
+	}
+
+	backtester.SetLookback(50)
+	stats := backtester.Run()
+	if strategy.firstSignal != 50 || strategy.history.Capacity() != 50 {
+		t.Errorf("Expected the first signal to wait for 50 candles, found %v", strategy.firstSignal)
+	}
+	if trades := stats.Trades(); len(trades) == 0 || trades[0].EntryCandle < 49 {
+		t.Errorf("No position should be opened before the lookback is full")
+	}
+}
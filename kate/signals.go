@@ -0,0 +1,265 @@
+package kate
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Signal is a entry or exit produced outside of kate, for example by a model trained offline
+type Signal struct {
+	Time      time.Time //moment the signal was produced
+	DataTime  time.Time //time of the latest data used to produce the signal, zero when unknown
+	Close     bool      //closes the open position instead of opening a new one
+	Direction Direction
+	Leverage  uint
+	Stop      float64 //stoploss price, zero for none
+	Target    float64 //takeprofit price, zero for none
+	Size      float64 //quantity of the position including leverage, zero uses the sizing of the backtester
+	Candle    int     //index of the candle the signal is acted on, defined by NewSignalStrategy
+}
+
+//SignalStrategy trades precomputed signals. Each signal is acted on the first candle closed at or after the time
+//it was produced: long and short signals open a position (closing a open position in the opposite direction) and
+//close signals close the open position with a market order.
+type SignalStrategy struct {
+	signals []Signal
+	byTime  map[int64]int //index of the signal acted on the candle closed at each time
+	current *Signal
+}
+
+//Columns accepted on the signal files, the time and direction are required
+var signalColumns = []string{"time", "direction", "leverage", "stop", "target", "size", "data_time"}
+
+//SignalsFromFile reads the signals of a csv file or, when the extension is .jsonl, .ndjson or .json, of a file
+//with one json object per line. The times are unix timestamps (seconds or milliseconds) or RFC3339 dates and the
+//direction is long, short or close.
+func SignalsFromFile(path string) ([]Signal, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return readJSONSignals(file)
+	}
+	return readCSVSignals(file)
+}
+
+//NewSignalStrategy joins the signals to the candles of the price data, which must have timestamps.
+//A error is returned when a signal was produced with data from after its own time, when it is after the last
+//candle or when two signals would be acted on the same candle.
+func NewSignalStrategy(signals []Signal, data *DataHandler) (*SignalStrategy, error) {
+	if !data.hasTimestamps() {
+		return nil, fmt.Errorf("the signals can only be joined to price data with timestamps")
+	}
+
+	joined := append([]Signal{}, signals...)
+	sort.SliceStable(joined, func(i, j int) bool {
+		return joined[i].Time.Before(joined[j].Time)
+	})
+
+	strategy := &SignalStrategy{signals: joined, byTime: map[int64]int{}}
+	for i := range joined {
+		signal := &joined[i]
+		if signal.DataTime.After(signal.Time) {
+			return nil, fmt.Errorf("the signal at %v references future data from %v", signal.Time, signal.DataTime)
+		}
+
+		signal.Candle = sort.Search(len(data.Prices), func(candle int) bool {
+			return !data.Prices[candle].Time().Before(signal.Time)
+		})
+		if signal.Candle == len(data.Prices) {
+			return nil, fmt.Errorf("the signal at %v is after the last candle closed at %v", signal.Time,
+				data.Prices[len(data.Prices)-1].Time())
+		}
+		if i > 0 && joined[i-1].Candle == signal.Candle {
+			return nil, fmt.Errorf("the signals at %v and %v are both acted on the candle closed at %v",
+				joined[i-1].Time, signal.Time, data.Prices[signal.Candle].Time())
+		}
+		strategy.byTime[data.Prices[signal.Candle].Time().UnixNano()] = i
+	}
+	return strategy, nil
+}
+
+//Signals returns the signals joined to the price data in the order they are acted on
+func (strategy *SignalStrategy) Signals() []Signal {
+	return strategy.signals
+}
+
+//PreProcessIndicators finds the signal acted on the candle
+func (strategy *SignalStrategy) PreProcessIndicators(latestPrice DataPoint) {
+	strategy.current = nil
+	if index, ok := strategy.byTime[latestPrice.Time().UnixNano()]; ok {
+		strategy.current = &strategy.signals[index]
+	}
+}
+
+//OpenNewPosition opens the position of a long or short signal
+func (strategy *SignalStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	signal := strategy.current
+	if signal == nil || signal.Close {
+		return nil
+	}
+	return &OpenPositionEvt{Direction: signal.Direction, Leverage: signal.Leverage, Quantity: signal.Size,
+		Stoploss: signal.Stop, TakeProfit: signal.Target}
+}
+
+//ClosePosition closes the open position on a close signal or a signal in the opposite direction
+func (strategy *SignalStrategy) ClosePosition(openPosition Position) bool {
+	signal := strategy.current
+	return signal != nil && (signal.Close || signal.Direction != openPosition.Direction)
+}
+
+//SetStoploss the stoploss is only defined by the signal that opened the position
+func (strategy *SignalStrategy) SetStoploss(openPosition Position) *StoplossEvt {
+	return nil
+}
+
+//SetTakeProfit the takeprofit is only defined by the signal that opened the position
+func (strategy *SignalStrategy) SetTakeProfit(openPosition Position) *TakeProfitEvt {
+	return nil
+}
+
+func readCSVSignals(file io.Reader) ([]Signal, error) {
+	reader := csv.NewReader(bufio.NewReader(file))
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the header of the signals: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var signals []Signal
+	for line := 2; ; line++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return signals, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading the signals: %v", err)
+		}
+
+		record := map[string]string{}
+		for i, column := range header {
+			record[column] = values[i]
+		}
+		signal, err := parseSignal(record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signal on line %v: %v", line, err)
+		}
+		signals = append(signals, signal)
+	}
+}
+
+func readJSONSignals(file io.Reader) ([]Signal, error) {
+	var signals []Signal
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var object map[string]jsonValue
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			return nil, fmt.Errorf("invalid json on line %v: %v", line, err)
+		}
+		record := map[string]string{}
+		for key, value := range object {
+			record[strings.ToLower(key)] = string(value)
+		}
+		signal, err := parseSignal(record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signal on line %v: %v", line, err)
+		}
+		signals = append(signals, signal)
+	}
+	return signals, scanner.Err()
+}
+
+//jsonValue is a json string or number (or null) kept as text to be parsed like the csv values
+type jsonValue string
+
+func (value *jsonValue) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*value = jsonValue(text)
+	} else if string(data) != "null" {
+		*value = jsonValue(data)
+	}
+	return nil
+}
+
+//parseSignal converts the values of the signal columns, the missing values use the defaults
+func parseSignal(record map[string]string) (Signal, error) {
+	signal := Signal{Leverage: 1}
+	for _, column := range signalColumns {
+		value := strings.TrimSpace(record[column])
+		if value == "" {
+			if column == "time" || column == "direction" {
+				return signal, fmt.Errorf("the %v is required", column)
+			}
+			continue
+		}
+
+		var err error
+		switch column {
+		case "time":
+			signal.Time, err = parseSignalTime(value)
+		case "data_time":
+			signal.DataTime, err = parseSignalTime(value)
+		case "direction":
+			err = parseSignalDirection(value, &signal)
+		case "leverage":
+			var leverage uint64
+			leverage, err = strconv.ParseUint(value, 10, 32)
+			signal.Leverage = uint(leverage)
+		case "stop":
+			signal.Stop, err = strToFloat(value)
+		case "target":
+			signal.Target, err = strToFloat(value)
+		case "size":
+			signal.Size, err = strToFloat(value)
+		}
+		if err != nil {
+			return signal, fmt.Errorf("invalid %v '%v': %v", column, value, err)
+		}
+	}
+	return signal, nil
+}
+
+//parseSignalTime accepts unix timestamps in seconds or milliseconds and RFC3339 dates
+func parseSignalTime(value string) (time.Time, error) {
+	if moment, err := strToTime(value); err == nil {
+		return moment, nil
+	}
+	moment, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a unix timestamp or a RFC3339 date")
+	}
+	return moment.UTC(), nil
+}
+
+func parseSignalDirection(value string, signal *Signal) error {
+	switch strings.ToLower(value) {
+	case "long", "buy", "1":
+		signal.Direction = LONG
+	case "short", "sell", "-1":
+		signal.Direction = SHORT
+	case "close", "exit", "flat", "0":
+		signal.Close = true
+	default:
+		return fmt.Errorf("expected long, short or close")
+	}
+	return nil
+}
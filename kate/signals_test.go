@@ -0,0 +1,123 @@
+package kate
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSignalStrategy(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	unix := func(candle int, offset int64) int64 {
+		return data.Prices[candle].Time().Unix() + offset
+	}
+	price := data.Prices[10].Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"signals.csv": strings.Join([]string{
+			"time,direction,leverage,stop,target,size,data_time",
+			formatInt(unix(10, 0)) + ",long,10," + formatPrice(price*0.9) + "," + formatPrice(price*1.1) + ",0.5," + formatInt(unix(10, 0)),
+			formatInt(unix(20, 0)) + ",close,,,,,",
+			formatInt(unix(30, 30)) + ",short,5,,,,",
+			data.Prices[40].Time().Format("2006-01-02T15:04:05Z") + ",long,,,,,",
+			formatInt(unix(50, 0)*1000) + ",close,,,,,",
+		}, "\n"),
+		"signals.jsonl": strings.Join([]string{
+			`{"time": ` + formatInt(unix(10, 0)) + `, "direction": "long", "leverage": 10, "stop": ` + formatPrice(price*0.9) +
+				`, "target": ` + formatPrice(price*1.1) + `, "size": 0.5, "data_time": ` + formatInt(unix(10, 0)) + `}`,
+			`{"time": ` + formatInt(unix(20, 0)) + `, "direction": "close"}`,
+			``,
+			`{"time": "` + formatInt(unix(30, 30)) + `", "direction": "SHORT", "leverage": 5, "stop": null}`,
+			`{"time": "` + data.Prices[40].Time().Format("2006-01-02T15:04:05Z") + `", "direction": "long"}`,
+			`{"time": ` + formatInt(unix(50, 0)*1000) + `, "direction": "flat"}`,
+		}, "\n"),
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		signals, err := SignalsFromFile(path)
+		if err != nil {
+			t.Fatalf("%v: could`t read the signals. %v", name, err)
+		}
+		strategy, err := NewSignalStrategy(signals, data)
+		if err != nil {
+			t.Fatalf("%v: could`t join the signals. %v", name, err)
+		}
+		if candle := strategy.Signals()[2].Candle; candle != 31 {
+			t.Errorf("%v: the signal between candles should be acted on the next candle, found %v", name, candle)
+		}
+
+		stats := NewBacktester(strategy, data).Run()
+		var tests = []struct {
+			entry, close int
+			direction    Direction
+			leverage     uint
+			reason       ExitReason
+		}{
+			{10, 20, LONG, 10, MarketExit},
+			{31, 40, SHORT, 5, MarketExit},
+			{40, 50, LONG, 1, MarketExit},
+		}
+		trades := stats.Trades()
+		if len(trades) != len(tests) {
+			t.Fatalf("%v: expected %v trades, found %v", name, len(tests), len(trades))
+		}
+		for i, test := range tests {
+			trade := trades[i]
+			if trade.EntryCandle != test.entry || trade.CloseCandle != test.close || trade.Direction != test.direction ||
+				trade.Leverage != test.leverage || trade.ExitReason != test.reason {
+				t.Errorf("%v: expected the trade %+v, found %+v", name, test, trade)
+			}
+		}
+		if !isEqual(trades[0].Size, 0.5) || !isEqual(trades[0].Stoploss, price*0.9) || !isEqual(trades[0].TakeProfit, price*1.1) {
+			t.Errorf("%v: the size, stop and target of the signal should be used, found %+v", name, trades[0])
+		}
+	}
+}
+
+func TestInvalidSignals(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD5.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	first, last := data.Prices[0].Time(), data.Prices[len(data.Prices)-1].Time()
+
+	var tests = []struct {
+		name    string
+		signals []Signal
+		message string
+	}{
+		{"future data", []Signal{{Time: first, DataTime: first.Add(60e9)}}, "references future data"},
+		{"after the last candle", []Signal{{Time: last.Add(1)}}, "after the last candle"},
+		{"same candle", []Signal{{Time: first.Add(60e9)}, {Time: first.Add(30e9)}}, "both acted on"},
+	}
+	for _, test := range tests {
+		if _, err := NewSignalStrategy(test.signals, data); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%v: expected a error containing '%v', found %v", test.name, test.message, err)
+		}
+	}
+
+	noTimestamps, _ := PricesFromCSV("../testdata/mockdata.csv")
+	if _, err := NewSignalStrategy(nil, noTimestamps); err == nil {
+		t.Errorf("Expected a error joining signals to price data without timestamps")
+	}
+
+	path := filepath.Join(t.TempDir(), "signals.csv")
+	os.WriteFile(path, []byte("time,direction\n1615690860,up\n"), 0644)
+	if _, err := SignalsFromFile(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected a error on the invalid direction of line 2, found %v", err)
+	}
+}
+
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
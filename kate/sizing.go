@@ -0,0 +1,175 @@
+package kate
+
+import (
+	"math"
+
+	"github.com/victorl2/kate-backtester/indicators"
+)
+
+//PositionSizer decides the amount of the balance (margin) used to open each position.
+//A amount of zero uses the fixed trade amount or the percentage of the balance per trade of the backtester.
+type PositionSizer interface {
+	Size(request SizeRequest) float64
+}
+
+//SizeRequest is the state of the account when a position is about to be opened
+type SizeRequest struct {
+	Balance   float64
+	Price     float64 //latest close price, the expected entry price
+	Direction Direction
+	Leverage  uint
+	Stoploss  float64    //stoploss requested with the position, zero when none was requested
+	Trades    []Position //positions closed until now
+	History   *Series    //latest candles
+}
+
+//FixedFractional uses a fixed percentage of the balance to open each position
+type FixedFractional struct {
+	Percentage float64
+}
+
+//FixedRisk sizes each position to lose a fixed percentage of the balance when the stoploss is reached.
+//The stoploss requested with the position is used, or the default distance when none was requested.
+type FixedRisk struct {
+	RiskPercentage        float64
+	DefaultStopPercentage float64 //distance of the stoploss from the entry price in percentage of the price
+}
+
+//VolatilityTarget sizes each position to lose a fixed percentage of the balance on a adverse move of a multiple of
+//the average true range, so positions are smaller when the market is more volatile
+type VolatilityTarget struct {
+	RiskPercentage float64
+	ATRPeriod      int
+	ATRMultiple    float64
+}
+
+//Kelly sizes each position with a fraction of the Kelly criterion calculated from the closed trades
+type Kelly struct {
+	Fraction          float64 //fraction of the Kelly criterion used, 0.5 is the half Kelly
+	Lookback          int     //amount of the latest trades used, zero uses every trade
+	MinTrades         int     //trades required before the criterion is used
+	DefaultPercentage float64 //percentage of the balance used until the minimum trades are reached
+	MaxPercentage     float64 //maximum percentage of the balance used in a position
+}
+
+//Martingale multiplies the size after each consecutive loss, or after each consecutive win when Anti is true
+type Martingale struct {
+	BasePercentage float64
+	Multiplier     float64
+	MaxSteps       int //maximum amount of multiplications
+	Anti           bool
+}
+
+//Size returns the percentage of the balance
+func (sizer FixedFractional) Size(request SizeRequest) float64 {
+	return request.Balance * sizer.Percentage / 100
+}
+
+//Size returns the margin of a position losing the risk percentage at the stoploss
+func (sizer FixedRisk) Size(request SizeRequest) float64 {
+	distance := sizer.DefaultStopPercentage / 100
+	if request.Stoploss > 0 && request.Price > 0 {
+		distance = math.Abs(request.Price-request.Stoploss) / request.Price
+	}
+	return riskMargin(request, sizer.RiskPercentage, distance)
+}
+
+//Size returns the margin of a position losing the risk percentage on a move of the ATR multiple,
+//zero is returned until the history has enough candles for the ATR
+func (sizer VolatilityTarget) Size(request SizeRequest) float64 {
+	if request.History == nil || request.History.Len() <= sizer.ATRPeriod || request.Price <= 0 {
+		return 0
+	}
+
+	atr := indicators.NewATR(sizer.ATRPeriod)
+	for n := request.History.Len() - 1; n >= 0; n-- {
+		atr.Update(request.History.At(n))
+	}
+	return riskMargin(request, sizer.RiskPercentage, sizer.ATRMultiple*atr.Value()/request.Price)
+}
+
+//Size returns the percentage of the balance given by the fraction of the Kelly criterion
+func (sizer Kelly) Size(request SizeRequest) float64 {
+	trades := request.Trades
+	if sizer.Lookback > 0 && len(trades) > sizer.Lookback {
+		trades = trades[len(trades)-sizer.Lookback:]
+	}
+	if len(trades) == 0 || len(trades) < sizer.MinTrades {
+		return request.Balance * sizer.DefaultPercentage / 100
+	}
+
+	var wins, losses []float64
+	for _, trade := range trades {
+		if trade.RealizedPNL > 0 {
+			wins = append(wins, trade.RealizedPNL)
+		} else if trade.RealizedPNL < 0 {
+			losses = append(losses, -trade.RealizedPNL)
+		}
+	}
+
+	fraction := 1.0
+	if len(wins) == 0 {
+		fraction = 0
+	} else if len(losses) > 0 {
+		winRate := float64(len(wins)) / float64(len(trades))
+		fraction = winRate - (1-winRate)/(average(wins)/average(losses))
+	}
+
+	percentage := math.Max(0, sizer.Fraction*fraction*100)
+	if sizer.MaxPercentage > 0 {
+		percentage = math.Min(percentage, sizer.MaxPercentage)
+	}
+	return request.Balance * percentage / 100
+}
+
+//Size returns the base percentage of the balance multiplied once for each trade of the latest streak
+func (sizer Martingale) Size(request SizeRequest) float64 {
+	streak := 0
+	for i := len(request.Trades) - 1; i >= 0; i-- {
+		won := request.Trades[i].RealizedPNL > 0
+		if won != sizer.Anti {
+			break
+		}
+		streak++
+	}
+	if sizer.MaxSteps > 0 && streak > sizer.MaxSteps {
+		streak = sizer.MaxSteps
+	}
+	return request.Balance * sizer.BasePercentage / 100 * math.Pow(sizer.Multiplier, float64(streak))
+}
+
+//riskMargin is the margin of a position that loses the risk percentage of the balance on a adverse move of the
+//distance (fraction of the price)
+func riskMargin(request SizeRequest, riskPercentage, distance float64) float64 {
+	if distance <= 0 {
+		return 0
+	}
+	leverage := math.Max(1, float64(request.Leverage))
+	return request.Balance * riskPercentage / 100 / distance / leverage
+}
+
+//tradeAmount is the amount of the balance used to open the position requested by the event,
+//zero uses the default sizing of the exchange
+func (sim *simulation) tradeAmount(event *OpenPositionEvt) float64 {
+	handler := sim.exchangeHandler
+	if event.Quantity > 0 {
+		return handler.marketHandler.notional(event.Quantity, handler.currentPrice) / math.Max(1, float64(event.Leverage))
+	}
+	if sim.sizer == nil {
+		return 0
+	}
+
+	trades := make([]Position, len(handler.tradeHistory))
+	for i, position := range handler.tradeHistory {
+		trades[i] = *position
+	}
+	return sim.sizer.Size(SizeRequest{
+		Balance:   handler.balance,
+		Price:     handler.currentPrice,
+		Direction: event.Direction,
+		Leverage:  event.Leverage,
+		Stoploss:  event.Stoploss,
+		Trades:    trades,
+		History:   sim.history,
+	})
+}
@@ -0,0 +1,153 @@
+package kate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//quantityStrategy opens every position of the simpleStrategy with a explicit quantity and stoploss
+type quantityStrategy struct {
+	simpleStrategy
+	quantity float64
+}
+
+func (strategy *quantityStrategy) OpenNewPosition(latestPrice DataPoint) *OpenPositionEvt {
+	event := strategy.simpleStrategy.OpenNewPosition(latestPrice)
+	if event != nil {
+		event.Quantity = strategy.quantity
+		event.Stoploss = latestPrice.Close() * 0.99
+	}
+	return event
+}
+
+func TestPositionSizers(t *testing.T) {
+	history := NewSeries(20)
+	for i := 0; i < 20; i++ {
+		price := 100.0 + float64(i%2)
+		history.Add(NewDataPoint(price, price+1, price-1, price, 10, time.Unix(int64(i*60), 0)))
+	}
+	losses := []Position{{RealizedPNL: 10}, {RealizedPNL: -5}, {RealizedPNL: -5}}
+	wins := []Position{{RealizedPNL: -5}, {RealizedPNL: 10}, {RealizedPNL: 10}}
+	mixed := []Position{{RealizedPNL: 20}, {RealizedPNL: -10}, {RealizedPNL: 20}, {RealizedPNL: -10}}
+
+	var tests = []struct {
+		name     string
+		sizer    PositionSizer
+		request  SizeRequest
+		expected float64
+	}{
+		{"fixed fractional", FixedFractional{Percentage: 5}, SizeRequest{Balance: 1000}, 50},
+		{"fixed risk with stoploss", FixedRisk{RiskPercentage: 1, DefaultStopPercentage: 5},
+			SizeRequest{Balance: 1000, Price: 100, Stoploss: 98, Leverage: 5}, 100},
+		{"fixed risk default stop", FixedRisk{RiskPercentage: 1, DefaultStopPercentage: 5},
+			SizeRequest{Balance: 1000, Price: 100, Leverage: 1}, 200},
+		{"volatility target", VolatilityTarget{RiskPercentage: 1, ATRPeriod: 14, ATRMultiple: 2},
+			SizeRequest{Balance: 1000, Price: 100, Leverage: 1, History: history}, 250},
+		{"volatility target warming up", VolatilityTarget{RiskPercentage: 1, ATRPeriod: 30, ATRMultiple: 2},
+			SizeRequest{Balance: 1000, Price: 100, Leverage: 1, History: history}, 0},
+		{"kelly without trades", Kelly{Fraction: 0.5, MinTrades: 2, DefaultPercentage: 1},
+			SizeRequest{Balance: 1000}, 10},
+		{"half kelly", Kelly{Fraction: 0.5, MinTrades: 2, DefaultPercentage: 1},
+			SizeRequest{Balance: 1000, Trades: mixed}, 125},
+		{"kelly capped", Kelly{Fraction: 1, MaxPercentage: 10},
+			SizeRequest{Balance: 1000, Trades: mixed}, 100},
+		{"kelly without edge", Kelly{Fraction: 1},
+			SizeRequest{Balance: 1000, Trades: losses}, 0},
+		{"martingale after losses", Martingale{BasePercentage: 1, Multiplier: 2},
+			SizeRequest{Balance: 1000, Trades: losses}, 40},
+		{"martingale max steps", Martingale{BasePercentage: 1, Multiplier: 2, MaxSteps: 1},
+			SizeRequest{Balance: 1000, Trades: losses}, 20},
+		{"martingale after win", Martingale{BasePercentage: 1, Multiplier: 2},
+			SizeRequest{Balance: 1000, Trades: wins}, 10},
+		{"anti martingale after wins", Martingale{BasePercentage: 1, Multiplier: 2, Anti: true},
+			SizeRequest{Balance: 1000, Trades: wins}, 40},
+	}
+
+	for _, test := range tests {
+		if size := test.sizer.Size(test.request); !isEqual(size, test.expected) {
+			t.Errorf("%v: expected size %v, found %v", test.name, test.expected, size)
+		}
+	}
+}
+
+func TestPositionSizerOnBacktester(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	backtester := NewBacktester(newSimpleStrategy(), data)
+	backtester.SetBalance(1000)
+	backtester.SetPositionSizer(FixedFractional{Percentage: 3})
+	stats := backtester.Run()
+	if len(stats.trades) == 0 {
+		t.Fatal("Expected trades to be opened")
+	}
+	first := stats.trades[0]
+	if margin := first.Margin * first.EntryPrice; !isEqual(margin, 30) {
+		t.Errorf("Expected the first position to use 3%% of the balance, found %v", margin)
+	}
+
+	backtester = NewBacktester(&quantityStrategy{quantity: 0.5}, data)
+	backtester.SetBalance(1000)
+	backtester.SetPositionSizer(FixedFractional{Percentage: 3})
+	stats = backtester.Run()
+	for _, trade := range stats.trades {
+		if !isEqual(trade.Size, 0.5) {
+			t.Fatalf("Expected the explicit quantity of 0.5, found %v", trade.Size)
+		}
+		if !isEqual(trade.Stoploss, trade.EntryPrice*0.99) {
+			t.Fatalf("Expected the stoploss requested with the position, found %v", trade.Stoploss)
+		}
+	}
+}
+
+func TestAmountExceedingBalance(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD2.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+
+	var tests = []struct {
+		name        string
+		quantity    float64
+		fixedAmount float64
+		rejected    bool
+	}{
+		{"quantity within the balance", 0.5, 0, false},
+		{"quantity exceeding the balance", 20, 0, true},
+		{"fixed amount within the balance", 0, 500, false},
+		{"fixed amount exceeding the balance", 0, 2000, true},
+	}
+	for _, test := range tests {
+		backtester := NewBacktester(&quantityStrategy{quantity: test.quantity}, data)
+		backtester.SetBalance(1000)
+		backtester.SetFixedTradeAmount(test.fixedAmount)
+		stats := backtester.Run()
+
+		rejections := 0
+		for _, order := range stats.Orders() {
+			if order.Action != OpenAction || order.Status != Rejected {
+				continue
+			}
+			rejections++
+			if !strings.Contains(order.Error, "exceeds the balance") {
+				t.Errorf("%v: expected the order to be rejected for exceeding the balance, found %+v", test.name, order)
+			}
+		}
+		if test.rejected != (stats.TotalTrades == 0) || test.rejected != (rejections > 0) {
+			t.Errorf("%v: unexpected %v trades and %v rejected orders", test.name, stats.TotalTrades, rejections)
+		}
+		if len(stats.RiskInterventions()) != 0 {
+			t.Errorf("%v: the rejections of the exchange are not interventions of a risk manager, found %+v",
+				test.name, stats.RiskInterventions())
+		}
+	}
+
+	//the quantity of coin margined markets is in contracts (USD), the margin is in coins
+	sim := &simulation{exchangeHandler: &ExchangeHandler{marketHandler: &CoinMarket{}, currentPrice: 2000}}
+	if margin := sim.tradeAmount(&OpenPositionEvt{Quantity: 1000, Leverage: 10}); !isEqual(margin, 0.05) {
+		t.Errorf("Expected the margin of 1000 contracts at 2000 with leverage 10 to be 0.05 coins, found %v", margin)
+	}
+}
@@ -1,5 +1,11 @@
 package kate
 
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
 //Statistics are the results based on trades executed on a backtest run
 type Statistics struct {
 	ROIPercentage   float64
@@ -9,20 +15,172 @@ type Statistics struct {
 	MaxDrawdown     float64 //Percentage for the maximum drawdown after applying the strategy
 	TotalTrades     int
 	TotalDataPoints int
+	Liquidations    int     //amount of positions closed by liquidations
+	LiquidationLoss float64 //total amount lost on liquidated positions including the liquidation fees
+
+	initialBalance float64
+	trades         []Position
+	orders         []Order
+	equityCurve    []EquityPoint
+	interventions  []RiskIntervention
+	market         MarketHandler //market of the trades, USD margined when unknown
+}
+
+//EquityPoint is the state of the account after a candle was processed
+type EquityPoint struct {
+	Candle  int //index of the candle in the price data
+	Time    time.Time
+	Balance float64 //balance without the open position
+	Equity  float64 //balance including the unrealized pnl and fees of the open position
+}
+
+//CostBreakdown is the attribution of the trading costs paid on a run
+type CostBreakdown struct {
+	MakerFees       float64
+	TakerFees       float64
+	LiquidationFees float64
+	Slippage        float64
+	Funding         float64 //negative values denote funding received
+	Total           float64
+	GrossPNL        float64 //profit or loss of the trades before any of the costs
+	CostRatio       float64 //fraction of the absolute gross pnl consumed by the costs
+}
+
+//Costs calculates how much of the result of the run was paid as fees, slippage and funding
+func (stats *Statistics) Costs() CostBreakdown {
+	var costs CostBreakdown
+	for _, trade := range stats.trades {
+		costs.MakerFees += trade.MakerFeePaid
+		costs.TakerFees += trade.TakerFeePaid
+		costs.LiquidationFees += trade.LiquidationFeePaid
+		costs.Slippage += trade.SlippageCost
+		costs.Funding += trade.FundingPaid
+		costs.GrossPNL += trade.RealizedPNL
+	}
+	costs.Total = costs.MakerFees + costs.TakerFees + costs.LiquidationFees + costs.Slippage + costs.Funding
+	costs.GrossPNL += costs.Total
+	if costs.GrossPNL != 0 {
+		costs.CostRatio = costs.Total / math.Abs(costs.GrossPNL)
+	}
+	return costs
+}
+
+//Directions used to decide which value of a metric is the best when comparing runs
+const (
+	lowerIsBetter  = -1
+	neutral        = 0
+	higherIsBetter = 1
+)
+
+//metric is a named value of the Statistics used when presenting the results of a run
+type metric struct {
+	name   string
+	value  float64
+	format string
+	better int
+}
+
+//String formats the value of the metric
+func (m metric) String() string {
+	return fmt.Sprintf(m.format, m.value)
 }
 
-//calculateStatistics calculates metrics based on a trade history
-func (bt *Backtester) calculateStatistics(initialBalance float64) *Statistics {
-	tradeHistory := bt.exchangeHandler.tradeHistory
+//metrics lists the summary values of the run in presentation order
+func (stats *Statistics) metrics() []metric {
+	return []metric{
+		{"Initial balance", stats.initialBalance, "%.2f", neutral},
+		{"Final balance", stats.initialBalance + stats.NetProfit, "%.2f", higherIsBetter},
+		{"Net profit", stats.NetProfit, "%.2f", higherIsBetter},
+		{"ROI", stats.ROIPercentage, "%.2f%%", higherIsBetter},
+		{"Sharpe ratio", stats.SharpeRatio, "%.4f", higherIsBetter},
+		{"Win rate", 100 * stats.WinRate, "%.2f%%", higherIsBetter},
+		{"Max drawdown", 100 * stats.MaxDrawdown, "%.2f%%", lowerIsBetter},
+		{"Total trades", float64(stats.TotalTrades), "%.0f", neutral},
+		{"Data points", float64(stats.TotalDataPoints), "%.0f", neutral},
+		{"Liquidations", float64(stats.Liquidations), "%.0f", lowerIsBetter},
+		{"Liquidation loss", stats.LiquidationLoss, "%.2f", lowerIsBetter},
+		{"Total costs", stats.Costs().Total, "%.2f", lowerIsBetter},
+	}
+}
+
+//tradeReturn is the percentage earned by a trade relative to the margin used to open it
+func tradeReturn(position Position) float64 {
+	margin := position.Margin * position.EntryPrice
+	if margin == 0 {
+		return 0
+	}
+	return 100 * position.RealizedPNL / margin
+}
+
+//InitialBalance is the balance available when the run started
+func (stats *Statistics) InitialBalance() float64 {
+	return stats.initialBalance
+}
+
+//Trades returns every position closed during the run in the order they were closed
+func (stats *Statistics) Trades() []Position {
+	return stats.trades
+}
+
+//Orders returns every order submitted to the exchange during the run, including the rejected ones and the
+//executions of the stoploss/takeprofit orders
+func (stats *Statistics) Orders() []Order {
+	return stats.orders
+}
+
+//RiskInterventions returns every position vetoed or resized by the risk manager during the run
+func (stats *Statistics) RiskInterventions() []RiskIntervention {
+	return stats.interventions
+}
+
+//EquityCurve returns the account state after each processed candle
+func (stats *Statistics) EquityCurve() []EquityPoint {
+	return stats.equityCurve
+}
+
+//calculateStatistics calculates metrics based on the trade history of the simulation
+func (sim *simulation) calculateStatistics(initialBalance float64) *Statistics {
+	trades := make([]Position, 0, len(sim.exchangeHandler.tradeHistory))
+	for _, position := range sim.exchangeHandler.tradeHistory {
+		trades = append(trades, *position)
+	}
+
+	orders := make([]Order, 0, len(sim.exchangeHandler.orderHistory))
+	for _, order := range sim.exchangeHandler.orderHistory {
+		orders = append(orders, *order)
+	}
+
+	stats := statisticsFromTrades(initialBalance, trades)
+	stats.TotalDataPoints = len(sim.dataHandler.Prices)
+	stats.orders = orders
+	stats.equityCurve = sim.equityCurve
+	stats.interventions = sim.risk.interventions
+	stats.market = sim.exchangeHandler.marketHandler
+	return stats
+}
+
+//notional is the value of a position size in the currency of the balance
+func (stats *Statistics) notional(size, price float64) float64 {
+	if stats.market == nil {
+		return size * price
+	}
+	return stats.market.notional(size, price)
+}
+
+//statisticsFromTrades calculates the metrics of a sequence of closed trades starting from the initial balance
+func statisticsFromTrades(initialBalance float64, trades []Position) *Statistics {
 	wins, balance, peakProfit, bottomProfit := 0, initialBalance, 0.0, 0.0
 	balanceHistory := []float64{initialBalance}
+	liquidations, liquidationLoss := 0, 0.0
 
-	for _, position := range tradeHistory {
+	for _, position := range trades {
 		if position.RealizedPNL >= 0 {
 			wins++
 		}
-		//fmt.Printf("open: %f | close: %f | liquidation: %f | margin: %f | size: %f | PNL %f\n", position.EntryPrice,
-		//	position.ClosePrice, position.LiquidationPrice, position.Margin*position.EntryPrice, position.Size, position.RealizedPNL)
+		if position.ExitReason == LiquidationExit {
+			liquidations++
+			liquidationLoss -= position.RealizedPNL
+		}
 
 		balance += position.RealizedPNL
 		balanceHistory = append(balanceHistory, balance)
@@ -34,13 +192,17 @@ func (bt *Backtester) calculateStatistics(initialBalance float64) *Statistics {
 			bottomProfit = balance
 		}
 	}
+
 	stats := &Statistics{
 		ROIPercentage:   100 * ((balance - initialBalance) / initialBalance),
 		NetProfit:       balance - initialBalance,
-		TotalTrades:     len(tradeHistory),
-		WinRate:         float64(wins) / float64(len(tradeHistory)),
+		TotalTrades:     len(trades),
+		WinRate:         float64(wins) / float64(len(trades)),
 		MaxDrawdown:     (peakProfit - bottomProfit) / peakProfit,
-		TotalDataPoints: len(bt.dataHandler.Prices),
+		Liquidations:    liquidations,
+		LiquidationLoss: liquidationLoss,
+		initialBalance:  initialBalance,
+		trades:          trades,
 	}
 
 	stats.SharpeRatio = sharpe(stats.NetProfit, 0.0, stdDev(balanceHistory))
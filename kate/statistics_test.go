@@ -0,0 +1,17 @@
+package kate
+
+import "testing"
+
+func TestCostBreakdown(t *testing.T) {
+	stats := &Statistics{trades: []Position{
+		{RealizedPNL: 8, TakerFeePaid: 1, MakerFeePaid: 0.5, TotalFeePaid: 1.5, SlippageCost: 0.5},
+		{RealizedPNL: -12, TakerFeePaid: 1, LiquidationFeePaid: 2, TotalFeePaid: 3, FundingPaid: 1, ExitReason: LiquidationExit},
+	}}
+
+	costs := stats.Costs()
+	expected := CostBreakdown{MakerFees: 0.5, TakerFees: 2, LiquidationFees: 2, Slippage: 0.5, Funding: 1, Total: 6,
+		GrossPNL: 2, CostRatio: 3}
+	if costs != expected {
+		t.Errorf("The cost breakdown is %+v the expected was %+v", costs, expected)
+	}
+}
@@ -10,6 +10,14 @@ const (
 	SHORT
 )
 
+//String returns the name of the trade direction
+func (direction Direction) String() string {
+	if direction == SHORT {
+		return "SHORT"
+	}
+	return "LONG"
+}
+
 //Strategy defines how/when trades should be opened and how stoploss/takeprofits should be set in a simulated run
 type Strategy interface {
 
@@ -31,3 +39,17 @@ type Strategy interface {
 	//A return value of -1 denotes that no takeprofit will be set
 	SetTakeProfit(openPosition Position) *TakeProfitEvt
 }
+
+//TimeframeStrategy is implemented by strategies that use the higher timeframes added with Backtester.AddTimeframe.
+//Each candle of a timeframe is delivered once it is closed, right before the PreProcessIndicators of the first
+//candle of the main price data closed at the same time or later, so the strategy never sees future prices.
+type TimeframeStrategy interface {
+	PreProcessTimeframe(timeframe string, closedCandle DataPoint)
+}
+
+//ExitStrategy is implemented by strategies that close positions with market orders. ClosePosition is called with
+//the open position on every new price data before SetStoploss and SetTakeProfit, when it returns true the position
+//is closed at the latest price and OpenNewPosition is called on the same candle allowing the position to be reversed.
+type ExitStrategy interface {
+	ClosePosition(openPosition Position) bool
+}
@@ -0,0 +1,273 @@
+package kate
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+)
+
+//Dimensions used by every chart rendered in the reports
+const (
+	chartWidth      = 960
+	chartHeight     = 260
+	chartPadLeft    = 70
+	chartPadRight   = 10
+	chartPadTop     = 10
+	chartPadBottom  = 24
+	maxChartSamples = 1200 //maximum amount of samples drawn by a line, bigger series are downsampled
+)
+
+//chartMarker is a highlighted point drawn over a line chart
+type chartMarker struct {
+	index int
+	value float64
+	color string
+	up    bool //markers point upwards when true and downwards otherwise
+	title string
+}
+
+//chartFrame maps values of a series to the coordinates of the svg
+type chartFrame struct {
+	count    int
+	min, max float64
+}
+
+func newChartFrame(values []float64) chartFrame {
+	frame := chartFrame{count: len(values), min: math.Inf(1), max: math.Inf(-1)}
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		frame.min = math.Min(frame.min, value)
+		frame.max = math.Max(frame.max, value)
+	}
+	if math.IsInf(frame.min, 0) {
+		frame.min, frame.max = 0, 1
+	}
+	if frame.min == frame.max {
+		frame.min, frame.max = frame.min-1, frame.max+1
+	}
+	return frame
+}
+
+func (frame chartFrame) x(index int) float64 {
+	width := float64(chartWidth - chartPadLeft - chartPadRight)
+	if frame.count <= 1 {
+		return chartPadLeft + width/2
+	}
+	return chartPadLeft + width*float64(index)/float64(frame.count-1)
+}
+
+func (frame chartFrame) y(value float64) float64 {
+	height := float64(chartHeight - chartPadTop - chartPadBottom)
+	return chartPadTop + height*(frame.max-value)/(frame.max-frame.min)
+}
+
+//lineChartSVG draws the values as a line, when fill is true the area between the line and zero is filled
+func lineChartSVG(values []float64, color string, fill bool, markers []chartMarker, labels [2]string) template.HTML {
+	if len(values) == 0 {
+		return emptyChartSVG("no data available")
+	}
+	frame := newChartFrame(values)
+	if fill {
+		frame = newChartFrame(append([]float64{0}, values...))
+		frame.count = len(values)
+	}
+
+	var svg strings.Builder
+	openSVG(&svg, chartHeight)
+	drawAxis(&svg, frame, labels)
+
+	var points strings.Builder
+	for _, index := range sampleIndexes(values) {
+		fmt.Fprintf(&points, "%.1f,%.1f ", frame.x(index), frame.y(values[index]))
+	}
+	if fill {
+		zero := frame.y(0)
+		fmt.Fprintf(&svg, `<polygon points="%.1f,%.1f %s%.1f,%.1f" fill="%s" fill-opacity="0.35" stroke="none"/>`,
+			frame.x(0), zero, points.String(), frame.x(len(values)-1), zero, color)
+	}
+	fmt.Fprintf(&svg, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.2"/>`, points.String(), color)
+
+	for _, marker := range markers {
+		x, y := frame.x(marker.index), frame.y(marker.value)
+		tip := y + 7
+		if marker.up {
+			tip = y - 7
+		}
+		fmt.Fprintf(&svg, `<path d="M%.1f,%.1f L%.1f,%.1f L%.1f,%.1f Z" fill="%s"><title>%s</title></path>`,
+			x, tip, x-4, y, x+4, y, marker.color, html.EscapeString(marker.title))
+	}
+	svg.WriteString("</svg>")
+	return template.HTML(svg.String())
+}
+
+//histogramSVG draws the distribution of the values grouped in the provided amount of bins
+func histogramSVG(values []float64, bins int) template.HTML {
+	if len(values) == 0 {
+		return emptyChartSVG("no trades available")
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		low, high = math.Min(low, value), math.Max(high, value)
+	}
+	if low == high {
+		low, high = low-0.5, high+0.5
+	}
+
+	counts := make([]int, bins)
+	width := (high - low) / float64(bins)
+	maxCount := 0
+	for _, value := range values {
+		bin := int((value - low) / width)
+		if bin >= bins {
+			bin = bins - 1
+		}
+		counts[bin]++
+		if counts[bin] > maxCount {
+			maxCount = counts[bin]
+		}
+	}
+
+	var svg strings.Builder
+	openSVG(&svg, chartHeight)
+	frame := chartFrame{count: bins + 1, min: 0, max: float64(maxCount)}
+	drawAxis(&svg, frame, [2]string{fmt.Sprintf("%.2f%%", low), fmt.Sprintf("%.2f%%", high)})
+
+	for bin, count := range counts {
+		color := "#2e7d32"
+		if low+width*float64(bin+1) <= 0 {
+			color = "#c62828"
+		}
+		x0, x1 := frame.x(bin), frame.x(bin+1)
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%.2f%% to %.2f%%: %d</title></rect>`,
+			x0+1, frame.y(float64(count)), math.Max(x1-x0-2, 1), frame.y(0)-frame.y(float64(count)), color,
+			low+width*float64(bin), low+width*float64(bin+1), count)
+	}
+	svg.WriteString("</svg>")
+	return template.HTML(svg.String())
+}
+
+//heatmapSVG draws a grid of percentages where each row has a label and a value for each column
+func heatmapSVG(rowLabels, columnLabels []string, values [][]float64) template.HTML {
+	if len(rowLabels) == 0 {
+		return emptyChartSVG("no timestamps available")
+	}
+	const cellHeight, labelWidth = 26.0, 60.0
+	cellWidth := (chartWidth - labelWidth - chartPadRight) / float64(len(columnLabels))
+	height := int(cellHeight * float64(len(rowLabels)+1))
+
+	scale := 0.0
+	for _, row := range values {
+		for _, value := range row {
+			if !math.IsNaN(value) {
+				scale = math.Max(scale, math.Abs(value))
+			}
+		}
+	}
+
+	var svg strings.Builder
+	openSVG(&svg, height)
+	for column, label := range columnLabels {
+		fmt.Fprintf(&svg, `<text x="%.1f" y="17" text-anchor="middle">%s</text>`,
+			labelWidth+cellWidth*(float64(column)+0.5), html.EscapeString(label))
+	}
+	for row, label := range rowLabels {
+		y := cellHeight * float64(row+1)
+		fmt.Fprintf(&svg, `<text x="4" y="%.1f">%s</text>`, y+17, html.EscapeString(label))
+		for column, value := range values[row] {
+			if math.IsNaN(value) {
+				continue
+			}
+			fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`,
+				labelWidth+cellWidth*float64(column)+1, y+1, cellWidth-2, cellHeight-2, heatColor(value, scale))
+			fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="middle">%.2f%%</text>`,
+				labelWidth+cellWidth*(float64(column)+0.5), y+17, value)
+		}
+	}
+	svg.WriteString("</svg>")
+	return template.HTML(svg.String())
+}
+
+//heatColor picks a green shade for positive values and a red shade for negative values
+func heatColor(value, scale float64) string {
+	intensity := 0.0
+	if scale > 0 {
+		intensity = math.Min(math.Abs(value)/scale, 1)
+	}
+	shade := int(235 - 135*intensity)
+	if value < 0 {
+		return fmt.Sprintf("rgb(245,%d,%d)", shade, shade)
+	}
+	return fmt.Sprintf("rgb(%d,240,%d)", shade, shade)
+}
+
+func emptyChartSVG(message string) template.HTML {
+	var svg strings.Builder
+	openSVG(&svg, 40)
+	fmt.Fprintf(&svg, `<text x="%d" y="24" text-anchor="middle">%s</text></svg>`, chartWidth/2, html.EscapeString(message))
+	return template.HTML(svg.String())
+}
+
+func openSVG(svg *strings.Builder, height int) {
+	fmt.Fprintf(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" font-family="sans-serif" font-size="11">`,
+		chartWidth, height)
+}
+
+//drawAxis draws the frame of the chart with the min/max values and the labels of the first and last samples
+func drawAxis(svg *strings.Builder, frame chartFrame, labels [2]string) {
+	bottom := chartHeight - chartPadBottom
+	fmt.Fprintf(svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#ccc"/>`, chartPadLeft, chartPadTop,
+		chartWidth-chartPadLeft-chartPadRight, bottom-chartPadTop)
+	fmt.Fprintf(svg, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`, chartPadLeft-4, frame.y(frame.max)+10, formatAxis(frame.max))
+	fmt.Fprintf(svg, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`, chartPadLeft-4, frame.y(frame.min), formatAxis(frame.min))
+	fmt.Fprintf(svg, `<text x="%d" y="%d">%s</text>`, chartPadLeft, chartHeight-6, html.EscapeString(labels[0]))
+	fmt.Fprintf(svg, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth-chartPadRight, chartHeight-6,
+		html.EscapeString(labels[1]))
+}
+
+func formatAxis(value float64) string {
+	if math.Abs(value) >= 1000 {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.4g", value)
+}
+
+//sampleIndexes selects the indexes drawn for a series keeping the min/max of each bucket when downsampling
+func sampleIndexes(values []float64) []int {
+	if len(values) <= maxChartSamples {
+		indexes := make([]int, len(values))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+
+	bucket := int(math.Ceil(float64(len(values)) / float64(maxChartSamples/2)))
+	indexes := make([]int, 0, maxChartSamples+1)
+	for start := 0; start < len(values); start += bucket {
+		end := start + bucket
+		if end > len(values) {
+			end = len(values)
+		}
+		low, high := start, start
+		for i := start; i < end; i++ {
+			if values[i] < values[low] {
+				low = i
+			}
+			if values[i] > values[high] {
+				high = i
+			}
+		}
+		if low > high {
+			low, high = high, low
+		}
+		indexes = append(indexes, low)
+		if high != low {
+			indexes = append(indexes, high)
+		}
+	}
+	return indexes
+}
@@ -69,3 +69,13 @@ func (marketHandler *USDMarket) limitFee(position *Position) float64 {
 func (marketHandler *USDMarket) liquidationFee(position *Position) float64 {
 	return 2 * marketHandler.marketFee(position)
 }
+
+//notional is the value in USD of a position size at the provided price
+func (marketHandler *USDMarket) notional(size, price float64) float64 {
+	return size * price
+}
+
+//funding calculates the funding payment for the position value at the provided price
+func (marketHandler *USDMarket) funding(position *Position, price, rate float64) float64 {
+	return position.Size * price * rate
+}
@@ -26,3 +26,48 @@ func mean(numbers []float64) float64 {
 func sharpe(investmentReturn, riskFreeReturn, stdDev float64) float64 {
 	return (investmentReturn - riskFreeReturn) / stdDev
 }
+
+//average is the arithmetic mean of the values
+func average(numbers []float64) float64 {
+	sum := 0.0
+	for _, number := range numbers {
+		sum += number
+	}
+	return sum / float64(len(numbers))
+}
+
+//sampleStdDev is the standard deviation of a sample around its arithmetic mean
+func sampleStdDev(numbers []float64) float64 {
+	if len(numbers) < 2 {
+		return 0
+	}
+	total, mean := 0.0, average(numbers)
+	for _, number := range numbers {
+		total += math.Pow(number-mean, 2)
+	}
+	return math.Sqrt(total / float64(len(numbers)-1))
+}
+
+//skewness is the third standardized moment of the values, zero when the values don't vary
+func skewness(numbers []float64) float64 {
+	return standardizedMoment(numbers, 3)
+}
+
+//kurtosis is the fourth standardized moment of the values (non excess, 3 for a normal distribution)
+func kurtosis(numbers []float64) float64 {
+	return standardizedMoment(numbers, 4)
+}
+
+func standardizedMoment(numbers []float64, order float64) float64 {
+	mean := average(numbers)
+	variance, moment := 0.0, 0.0
+	for _, number := range numbers {
+		variance += math.Pow(number-mean, 2)
+		moment += math.Pow(number-mean, order)
+	}
+	variance /= float64(len(numbers))
+	if variance == 0 {
+		return 0
+	}
+	return moment / float64(len(numbers)) / math.Pow(variance, order/2)
+}
@@ -0,0 +1,202 @@
+package kate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//WalkForward optimizes the parameters on a in-sample window of the price data and runs the best parameters on
+//the following out-of-sample window, repeating the process until the end of the data
+type WalkForward struct {
+	Search      *GridSearch //search executed on each in-sample window, its Data is replaced by the window data
+	Data        *DataHandler
+	InSample    int  //amount of candles used to optimize the parameters
+	OutOfSample int  //amount of candles used to validate the parameters, also the step between windows
+	Anchored    bool //when true every in-sample window starts on the first candle instead of rolling
+}
+
+//WalkForwardWindow is the result of a single in-sample optimization followed by the out-of-sample run.
+//The efficiency is zero when the in-sample return is zero.
+type WalkForwardWindow struct {
+	InSampleStart, InSampleEnd       int //candle indexes of the in-sample window, the end is exclusive
+	OutOfSampleStart, OutOfSampleEnd int //candle indexes of the out-of-sample window, the end is exclusive
+	Params                           Params
+	InSample                         *Statistics //result of the best parameters in the in-sample window
+	OutOfSample                      *Statistics
+	Efficiency                       float64 //out-of-sample return per candle relative to the in-sample return per candle
+}
+
+//ParameterStability describes how the parameters selected on each window changed during the walk-forward
+type ParameterStability struct {
+	Name     string
+	Distinct int //amount of different values selected
+	Changes  int //amount of times the selected value changed from a window to the next
+	Mean     float64
+	StdDev   float64 //zero for non numeric parameters
+}
+
+//WalkForwardResult contains every window of the analysis and the stitched out-of-sample equity curve.
+//The efficiency is zero when the average in-sample return is zero.
+type WalkForwardResult struct {
+	Windows        []WalkForwardWindow
+	EquityCurve    []EquityPoint //out-of-sample equity of all windows indexed by the candles of the full data
+	InitialBalance float64
+	FinalBalance   float64
+	Efficiency     float64 //average out-of-sample return per candle relative to the average in-sample return per candle
+	Stability      []ParameterStability
+}
+
+//NewWalkForward creates a rolling walk-forward analysis using the search to optimize each in-sample window
+func NewWalkForward(search *GridSearch, data *DataHandler, inSample, outOfSample int) *WalkForward {
+	return &WalkForward{Search: search, Data: data, InSample: inSample, OutOfSample: outOfSample}
+}
+
+//Run executes the optimization and validation of every window
+func (wf *WalkForward) Run() (*WalkForwardResult, error) {
+	if wf.Search == nil || wf.Data == nil {
+		return nil, fmt.Errorf("the walk-forward requires a grid search and price data")
+	}
+	if wf.InSample <= 0 || wf.OutOfSample <= 0 || wf.InSample+wf.OutOfSample > len(wf.Data.Prices) {
+		return nil, fmt.Errorf("the in-sample (%v) and out-of-sample (%v) windows don't fit in the %v candles available",
+			wf.InSample, wf.OutOfSample, len(wf.Data.Prices))
+	}
+
+	result := &WalkForwardResult{}
+	balance := math.NaN()
+	var inSampleRates, outOfSampleRates []float64
+
+	for start := 0; start+wf.InSample+wf.OutOfSample <= len(wf.Data.Prices); start += wf.OutOfSample {
+		window := WalkForwardWindow{InSampleStart: start, InSampleEnd: start + wf.InSample}
+		if wf.Anchored {
+			window.InSampleStart = 0
+		}
+		window.OutOfSampleStart = window.InSampleEnd
+		window.OutOfSampleEnd = window.InSampleEnd + wf.OutOfSample
+
+		search := *wf.Search
+		search.Data = wf.slice(window.InSampleStart, window.InSampleEnd)
+		results, err := search.Run()
+		if err != nil {
+			return nil, err
+		}
+		best := results.Best()
+		if best.Err != nil {
+			return nil, fmt.Errorf("every run of the in-sample window %v-%v failed: %v", window.InSampleStart,
+				window.InSampleEnd, best.Err)
+		}
+		window.Params, window.InSample = best.Params, best.Stats
+
+		backtester := NewBacktester(search.Factory(best.Params), wf.slice(window.OutOfSampleStart, window.OutOfSampleEnd))
+		if search.Setup != nil {
+			search.Setup(backtester)
+		}
+		//the position open at the end of the window is closed so its result is part of the stitched balance
+		backtester.closeAtEnd = true
+		if math.IsNaN(balance) {
+			result.InitialBalance = backtester.exchangeHandler.balance
+		} else {
+			backtester.SetBalance(balance)
+		}
+		window.OutOfSample = backtester.Run()
+		balance = window.OutOfSample.initialBalance + window.OutOfSample.NetProfit
+
+		for _, point := range window.OutOfSample.equityCurve {
+			point.Candle += window.OutOfSampleStart
+			result.EquityCurve = append(result.EquityCurve, point)
+		}
+
+		inSampleRate := window.InSample.ROIPercentage / float64(window.InSampleEnd-window.InSampleStart)
+		outOfSampleRate := window.OutOfSample.ROIPercentage / float64(wf.OutOfSample)
+		window.Efficiency = efficiency(outOfSampleRate, inSampleRate)
+		inSampleRates = append(inSampleRates, inSampleRate)
+		outOfSampleRates = append(outOfSampleRates, outOfSampleRate)
+		result.Windows = append(result.Windows, window)
+	}
+
+	result.FinalBalance = balance
+	result.Efficiency = efficiency(average(outOfSampleRates), average(inSampleRates))
+	result.Stability = parameterStability(result.Windows)
+	return result, nil
+}
+
+//String renders a table with the windows followed by the efficiency and the stability of the parameters
+func (result *WalkForwardResult) String() string {
+	rows := [][]string{{"Window", "In-sample", "Out-of-sample", "Params", "IS ROI", "OOS ROI", "Efficiency"}}
+	for i, window := range result.Windows {
+		rows = append(rows, []string{strconv.Itoa(i + 1),
+			fmt.Sprintf("%v-%v", window.InSampleStart, window.InSampleEnd),
+			fmt.Sprintf("%v-%v", window.OutOfSampleStart, window.OutOfSampleEnd), window.Params.String(),
+			fmt.Sprintf("%.2f%%", window.InSample.ROIPercentage), fmt.Sprintf("%.2f%%", window.OutOfSample.ROIPercentage),
+			fmt.Sprintf("%.4f", window.Efficiency)})
+	}
+
+	var text strings.Builder
+	text.WriteString(renderTable(rows, nil))
+	fmt.Fprintf(&text, "\nBalance: %.2f -> %.2f | Walk-forward efficiency: %.4f\n", result.InitialBalance,
+		result.FinalBalance, result.Efficiency)
+
+	stability := [][]string{{"Parameter", "Distinct", "Changes", "Mean", "StdDev"}}
+	for _, parameter := range result.Stability {
+		stability = append(stability, []string{parameter.Name, strconv.Itoa(parameter.Distinct),
+			strconv.Itoa(parameter.Changes), fmt.Sprintf("%.4f", parameter.Mean), fmt.Sprintf("%.4f", parameter.StdDev)})
+	}
+	text.WriteString(renderTable(stability, nil))
+	return text.String()
+}
+
+//efficiency is the out-of-sample return relative to the in-sample return, zero when the in-sample return is zero
+//as the ratio is not defined
+func efficiency(outOfSampleRate, inSampleRate float64) float64 {
+	if inSampleRate == 0 {
+		return 0
+	}
+	return outOfSampleRate / inSampleRate
+}
+
+//slice creates a DataHandler sharing the prices between the start and end (exclusive) candles
+func (wf *WalkForward) slice(start, end int) *DataHandler {
+	return newDataHandler(wf.Data.Prices[start:end])
+}
+
+//parameterStability summarizes the values selected for each parameter across the windows
+func parameterStability(windows []WalkForwardWindow) []ParameterStability {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	var stability []ParameterStability
+	for _, name := range (OptimizationResults{{Params: windows[0].Params}}).paramNames() {
+		summary := ParameterStability{Name: name}
+		distinct := map[string]bool{}
+		var values []float64
+		numeric := true
+
+		for i, window := range windows {
+			text := window.Params.Text(name)
+			distinct[text] = true
+			if i > 0 && text != windows[i-1].Params.Text(name) {
+				summary.Changes++
+			}
+			switch value := window.Params[name].(type) {
+			case int:
+				values = append(values, float64(value))
+			case float64:
+				values = append(values, value)
+			default:
+				numeric = false
+			}
+		}
+
+		summary.Distinct = len(distinct)
+		if numeric {
+			summary.Mean = average(values)
+			if len(values) > 1 {
+				summary.StdDev = sampleStdDev(values)
+			}
+		}
+		stability = append(stability, summary)
+	}
+	return stability
+}
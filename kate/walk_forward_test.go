@@ -0,0 +1,145 @@
+package kate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWalkForward(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD4.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	objective, _ := MetricObjective("Net profit")
+	search := NewGridSearch(newTargetStrategy, nil, objective, IntRange("leverage", 10, 20, 10),
+		FloatRange("target", 0.5, 1, 0.5))
+	search.Setup = func(backtester *Backtester) {
+		backtester.SetBalance(300)
+		backtester.SetFixedTradeAmount(10)
+	}
+
+	var tests = []struct {
+		anchored        bool
+		expectedWindows int
+	}{
+		{false, 7},
+		{true, 7},
+	}
+
+	for _, test := range tests {
+		walkForward := NewWalkForward(search, data, 6000, 2000)
+		walkForward.Anchored = test.anchored
+		result, err := walkForward.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.Windows) != test.expectedWindows {
+			t.Fatalf("The walk-forward produced %v windows the expected amount is %v", len(result.Windows), test.expectedWindows)
+		}
+
+		balance := result.InitialBalance
+		for i, window := range result.Windows {
+			expectedStart := i * 2000
+			if test.anchored {
+				expectedStart = 0
+			}
+			if window.InSampleStart != expectedStart || window.OutOfSampleStart != window.InSampleEnd ||
+				window.OutOfSampleEnd-window.OutOfSampleStart != 2000 || window.Params == nil {
+				t.Errorf("The window %v has wrong boundaries: %+v", i, window)
+			}
+			if window.OutOfSample.InitialBalance() != balance {
+				t.Errorf("The window %v started with %v instead of the previous balance %v", i, window.OutOfSample.InitialBalance(), balance)
+			}
+			balance += window.OutOfSample.NetProfit
+		}
+
+		if !isEqual(result.FinalBalance, balance) || result.InitialBalance != 300 {
+			t.Errorf("The stitched balance goes from %v to %v the expected was 300 to %v", result.InitialBalance, result.FinalBalance, balance)
+		}
+
+		first, last := result.EquityCurve[0], result.EquityCurve[len(result.EquityCurve)-1]
+		if first.Candle != 6000 || last.Candle < 19000 {
+			t.Errorf("The out-of-sample equity curve should cover the candles 6000 to 20000, found %v to %v", first.Candle, last.Candle)
+		}
+
+		if table := result.String(); !strings.Contains(table, "Walk-forward efficiency") || !strings.Contains(table, "leverage") {
+			t.Errorf("The walk-forward table is missing the summary:\n%v", table)
+		}
+
+		if len(result.Stability) != 2 || result.Stability[0].Name != "leverage" || result.Stability[0].Distinct < 1 ||
+			result.Stability[0].Mean < 10 || result.Stability[0].Mean > 20 {
+			t.Errorf("The parameter stability contains wrong values: %+v", result.Stability)
+		}
+	}
+
+	if _, err := NewWalkForward(search, data, 20000, 2000).Run(); err == nil {
+		t.Errorf("A error was expected when the windows don't fit in the data")
+	}
+}
+
+func TestWalkForwardClosesOpenPositions(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD4.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	//opens a single position that is never closed by the strategy
+	holding := func(params Params) Strategy {
+		return &scriptedStrategy{open: func(candle int) *OpenPositionEvt {
+			return &OpenPositionEvt{Direction: LONG, Leverage: uint(params.Int("leverage"))}
+		}}
+	}
+	objective, _ := MetricObjective("Net profit")
+	search := NewGridSearch(holding, nil, objective, IntRange("leverage", 1, 2, 1))
+
+	result, err := NewWalkForward(search, data, 6000, 2000).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := result.InitialBalance
+	for i, window := range result.Windows {
+		trades := window.OutOfSample.Trades()
+		if len(trades) != 1 || trades[0].ExitReason != EndOfDataExit {
+			t.Fatalf("The window %v should close its open position at the end, found %+v", i, trades)
+		}
+		lastCandle := window.OutOfSampleEnd - window.OutOfSampleStart - 1
+		if trades[0].CloseCandle != lastCandle || trades[0].ClosePrice != data.Prices[window.OutOfSampleEnd-1].Close() {
+			t.Errorf("The window %v should close its position at the last price %v, found %v on candle %v", i,
+				data.Prices[window.OutOfSampleEnd-1].Close(), trades[0].ClosePrice, trades[0].CloseCandle)
+		}
+		balance += window.OutOfSample.NetProfit
+	}
+	last := result.EquityCurve[len(result.EquityCurve)-1]
+	if balance == result.InitialBalance || !isEqual(result.FinalBalance, balance) || !isEqual(last.Equity, balance) {
+		t.Errorf("The stitched balance %v and equity %v should include the positions closed at the end, expected %v",
+			result.FinalBalance, last.Equity, balance)
+	}
+}
+
+func TestWalkForwardFlatInSample(t *testing.T) {
+	data, err := PricesFromCSV("../testdata/ETHUSD4.csv")
+	if err != nil {
+		t.Fatal("could`t load data." + err.Error())
+	}
+	//never trades so every window has a return of zero
+	idle := func(params Params) Strategy {
+		return &scriptedStrategy{}
+	}
+	objective, _ := MetricObjective("Net profit")
+	search := NewGridSearch(idle, nil, objective, IntRange("leverage", 1, 2, 1))
+
+	result, err := NewWalkForward(search, data, 6000, 2000).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, window := range result.Windows {
+		if window.InSample.ROIPercentage != 0 || window.Efficiency != 0 {
+			t.Errorf("The window %v with a flat in-sample return should have a efficiency of zero, found %v", i,
+				window.Efficiency)
+		}
+	}
+	if table := result.String(); result.Efficiency != 0 || strings.Contains(table, "NaN") ||
+		strings.Contains(table, "Inf") {
+		t.Errorf("The walk-forward efficiency should be zero, found %v:\n%v", result.Efficiency, table)
+	}
+}
@@ -0,0 +1,218 @@
+#!/usr/bin/env python3
+"""Reference values of TestIndicatorsReferenceValues (indicators/indicators_test.go).
+
+Every indicator is recalculated from scratch over all the candles up to each checkpoint with a straightforward
+implementation, without the streaming windows and monotonic queues of the indicators package.
+
+    python3 testdata/indicators_reference.py
+"""
+import csv
+import math
+import os
+from datetime import datetime, timezone
+
+CHECKPOINTS = [99, 500, -1]
+
+
+def load(path):
+    with open(path) as file:
+        rows = list(csv.DictReader(file))
+    return [{key: float(value) for key, value in row.items()} for row in rows]
+
+
+def ema(values, period, alpha=None):
+    """exponential average seeded with the simple average of the first period"""
+    alpha = 2 / (period + 1) if alpha is None else alpha
+    value = 0.0
+    for i, current in enumerate(values):
+        if i < period:
+            value += (current - value) / (i + 1)
+        else:
+            value += alpha * (current - value)
+    return value
+
+
+def wilder(values, period):
+    return ema(values, period, 1 / period)
+
+
+def series(function, values, start):
+    """function applied to every prefix of the values from the start length"""
+    return [function(values[:i + 1]) for i in range(start - 1, len(values))]
+
+
+def true_ranges(candles):
+    ranges = [candles[0]["high"] - candles[0]["low"]]
+    for previous, candle in zip(candles, candles[1:]):
+        ranges.append(max(candle["high"] - candle["low"], abs(candle["high"] - previous["close"]),
+                          abs(candle["low"] - previous["close"])))
+    return ranges
+
+
+def sma(closes, period):
+    return sum(closes[-period:]) / period
+
+
+def wma(closes, period):
+    return sum((i + 1) * value for i, value in enumerate(closes[-period:])) / (period * (period + 1) / 2)
+
+
+def rsi(closes, period):
+    changes = [current - previous for previous, current in zip(closes, closes[1:])]
+    gains = wilder([max(change, 0) for change in changes], period)
+    losses = wilder([max(-change, 0) for change in changes], period)
+    if losses == 0:
+        return 50 if gains == 0 else 100
+    return 100 - 100 / (1 + gains / losses)
+
+
+def macd(closes, fast, slow, signal):
+    lines = [ema(closes[:i + 1], fast) - ema(closes[:i + 1], slow) for i in range(max(fast, slow) - 1, len(closes))]
+    return lines[-1], ema(lines, signal)
+
+
+def bollinger(closes, period, multiplier):
+    window = closes[-period:]
+    middle = sum(window) / period
+    deviation = math.sqrt(sum((value - middle) ** 2 for value in window) / period)
+    return middle - multiplier * deviation, middle, middle + multiplier * deviation
+
+
+def stochastic(candles, k, d):
+    def percent_k(prefix):
+        window = prefix[-k:]
+        highest, lowest = max(c["high"] for c in window), min(c["low"] for c in window)
+        return 50 if highest == lowest else 100 * (prefix[-1]["close"] - lowest) / (highest - lowest)
+    values = [percent_k(candles[:i + 1]) for i in range(len(candles) - d, len(candles))]
+    return values[-1], sum(values) / d
+
+
+def adx(candles, period):
+    plus, minus = [], []
+    for previous, candle in zip(candles, candles[1:]):
+        up, down = candle["high"] - previous["high"], previous["low"] - candle["low"]
+        plus.append(up if up > down and up > 0 else 0)
+        minus.append(down if down > up and down > 0 else 0)
+    ranges = true_ranges(candles)[1:]
+
+    def indicators(length):
+        average = wilder(ranges[:length], period)
+        if average == 0:
+            return 0, 0
+        return 100 * wilder(plus[:length], period) / average, 100 * wilder(minus[:length], period) / average
+
+    def dx(length):
+        plus_di, minus_di = indicators(length)
+        return 0 if plus_di + minus_di == 0 else 100 * abs(plus_di - minus_di) / (plus_di + minus_di)
+
+    directional = [dx(length) for length in range(period, len(ranges) + 1)]
+    plus_di, minus_di = indicators(len(ranges))
+    return wilder(directional, period), plus_di, minus_di
+
+
+def obv(candles):
+    value = 0.0
+    for previous, candle in zip(candles, candles[1:]):
+        if candle["close"] > previous["close"]:
+            value += candle["volume"]
+        elif candle["close"] < previous["close"]:
+            value -= candle["volume"]
+    return value
+
+
+def vwap(candles):
+    day = datetime.fromtimestamp(candles[-1]["close_time"], timezone.utc).date()
+    today = [c for c in candles if datetime.fromtimestamp(c["close_time"], timezone.utc).date() == day]
+    weighted = sum((c["high"] + c["low"] + c["close"]) / 3 * c["volume"] for c in today)
+    return weighted / sum(c["volume"] for c in today)
+
+
+def super_trend(candles, period, multiplier):
+    ranges = true_ranges(candles)
+    upper = lower = previous = None
+    uptrend = True
+    for i in range(period - 1, len(candles)):
+        candle, atr = candles[i], wilder(ranges[:i + 1], period)
+        median = (candle["high"] + candle["low"]) / 2
+        band_up, band_down = median + multiplier * atr, median - multiplier * atr
+        if upper is not None:
+            if band_up > upper and previous <= upper:
+                band_up = upper
+            if band_down < lower and previous >= lower:
+                band_down = lower
+            if uptrend and candle["close"] < band_down:
+                uptrend = False
+            elif not uptrend and candle["close"] > band_up:
+                uptrend = True
+        upper, lower, previous = band_up, band_down, candle["close"]
+    return lower if uptrend else upper
+
+
+def keltner(candles, period, atr_period, multiplier):
+    middle = ema([c["close"] for c in candles], period)
+    atr = wilder(true_ranges(candles), atr_period)
+    return middle - multiplier * atr, middle, middle + multiplier * atr
+
+
+def midpoint(candles, period):
+    window = candles[-period:]
+    return (max(c["high"] for c in window) + min(c["low"] for c in window)) / 2
+
+
+def ichimoku(candles, conversion, base, span):
+    projected = candles[:len(candles) - base]
+    span_a = (midpoint(projected, conversion) + midpoint(projected, base)) / 2
+    return midpoint(candles, conversion), midpoint(candles, base), span_a, midpoint(projected, span)
+
+
+def reference(candles):
+    closes = [c["close"] for c in candles]
+    macd_line, macd_signal = macd(closes, 12, 26, 9)
+    bollinger_lower, bollinger_middle, bollinger_upper = bollinger(closes, 20, 2)
+    stochastic_k, stochastic_d = stochastic(candles, 14, 3)
+    adx_value, plus_di, minus_di = adx(candles, 14)
+    keltner_lower, keltner_middle, keltner_upper = keltner(candles, 20, 10, 2)
+    conversion, base, span_a, span_b = ichimoku(candles, 9, 26, 52)
+    donchian = candles[-20:]
+    return [
+        ("SMA(20)", sma(closes, 20)),
+        ("EMA(20)", ema(closes, 20)),
+        ("WMA(20)", wma(closes, 20)),
+        ("RSI(14)", rsi(closes, 14)),
+        ("MACD", macd_line),
+        ("MACD signal", macd_signal),
+        ("MACD histogram", macd_line - macd_signal),
+        ("Bollinger upper", bollinger_upper),
+        ("Bollinger middle", bollinger_middle),
+        ("Bollinger lower", bollinger_lower),
+        ("ATR(14)", wilder(true_ranges(candles), 14)),
+        ("Stochastic K", stochastic_k),
+        ("Stochastic D", stochastic_d),
+        ("ADX(14)", adx_value),
+        ("ADX +DI", plus_di),
+        ("ADX -DI", minus_di),
+        ("OBV", obv(candles)),
+        ("VWAP", vwap(candles)),
+        ("SuperTrend", super_trend(candles, 10, 3)),
+        ("Keltner upper", keltner_upper),
+        ("Keltner middle", keltner_middle),
+        ("Keltner lower", keltner_lower),
+        ("Donchian upper", max(c["high"] for c in donchian)),
+        ("Donchian middle", midpoint(candles, 20)),
+        ("Donchian lower", min(c["low"] for c in donchian)),
+        ("Ichimoku conversion", conversion),
+        ("Ichimoku base", base),
+        ("Ichimoku span A", span_a),
+        ("Ichimoku span B", span_b),
+    ]
+
+
+def main():
+    candles = load(os.path.join(os.path.dirname(os.path.abspath(__file__)), "ETHUSD2.csv"))
+    checkpoints = [reference(candles[:index + 1 if index >= 0 else len(candles)]) for index in CHECKPOINTS]
+    for row in zip(*checkpoints):
+        print("%-20s %s" % (row[0][0], ", ".join(repr(float(value)) for _, value in row)))
+
+
+if __name__ == "__main__":
+    main()